@@ -0,0 +1,164 @@
+package aur
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// _defaultMaxURILength is the longest request URI accepted by the AUR.
+	_defaultMaxURILength = 4443
+
+	_defaultMaxConcurrentRequests = 4
+)
+
+// ChunkError is the failure of one request of a split Info.
+type ChunkError struct {
+	Targets []string
+	Err     error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("info for %s: %s", strings.Join(e.Targets, ", "), e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned by Info when some of its requests failed. The
+// results of the other requests are still returned.
+type BatchError struct {
+	Chunks []ChunkError
+}
+
+func (e *BatchError) Error() string {
+	if len(e.Chunks) == 1 {
+		return e.Chunks[0].Error()
+	}
+
+	return fmt.Sprintf("%d info requests failed, first: %s", len(e.Chunks), e.Chunks[0].Error())
+}
+
+// Unwrap returns the error of the first failed request, so that errors.Is
+// works on the usual causes such as ErrServiceUnavailable.
+func (e *BatchError) Unwrap() error {
+	return e.Chunks[0].Err
+}
+
+func (c *Client) maxURILength() int {
+	if c.MaxURILength > 0 {
+		return c.MaxURILength
+	}
+
+	return _defaultMaxURILength
+}
+
+func (c *Client) maxConcurrentRequests() int {
+	if c.MaxConcurrentRequests > 0 {
+		return c.MaxConcurrentRequests
+	}
+
+	return _defaultMaxConcurrentRequests
+}
+
+// splitInfoTargets splits pkgs into consecutive chunks whose info request
+// URL is at most maxLength long. A target too long on its own still gets a
+// chunk, to let the server report the error.
+func splitInfoTargets(baseURL string, pkgs []string, maxLength int) [][]string {
+	// the query is encoded as arg%5B%5D=a&arg%5B%5D=b&type=info&v=6
+	fixed := len(baseURL) + len("type=info&v=6")
+	chunks := [][]string{}
+	start, length := 0, fixed
+
+	for i, pkg := range pkgs {
+		arg := len("arg%5B%5D=") + len(url.QueryEscape(pkg)) + len("&")
+		if i > start && length+arg > maxLength {
+			chunks = append(chunks, pkgs[start:i])
+			start, length = i, fixed
+		}
+
+		length += arg
+	}
+
+	if start < len(pkgs) {
+		chunks = append(chunks, pkgs[start:])
+	}
+
+	return chunks
+}
+
+func (c *Client) infoBatch(ctx context.Context, pkgs []string, chunks [][]string,
+	reqEditors []RequestEditorFn) ([]Pkg, error) {
+	results := make([][]Pkg, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, c.maxConcurrentRequests())
+
+	var wg sync.WaitGroup
+
+	for i := range chunks {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = c.info(ctx, chunks[i], reqEditors)
+		}(i)
+	}
+
+	wg.Wait()
+
+	merged := []Pkg{}
+	batchErr := &BatchError{}
+
+	for i := range chunks {
+		if errs[i] != nil {
+			batchErr.Chunks = append(batchErr.Chunks, ChunkError{Targets: chunks[i], Err: errs[i]})
+
+			continue
+		}
+
+		merged = append(merged, results[i]...)
+	}
+
+	merged = sortByTargets(merged, pkgs)
+
+	if len(batchErr.Chunks) > 0 {
+		return merged, batchErr
+	}
+
+	return merged, nil
+}
+
+// sortByTargets orders results like the targets they were requested with.
+// Results not named after a target keep their place at the end.
+func sortByTargets(results []Pkg, pkgs []string) []Pkg {
+	index := make(map[string]int, len(pkgs))
+
+	for i, pkg := range pkgs {
+		if _, ok := index[pkg]; !ok {
+			index[pkg] = i
+		}
+	}
+
+	position := func(pkg *Pkg) int {
+		if i, ok := index[pkg.Name]; ok {
+			return i
+		}
+
+		return len(pkgs)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return position(&results[i]) < position(&results[j])
+	})
+
+	return results
+}
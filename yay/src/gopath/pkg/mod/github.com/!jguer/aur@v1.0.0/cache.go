@@ -0,0 +1,325 @@
+package aur
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotCached is returned by an offline CachedClient for requests it has
+// no cached response for.
+var ErrNotCached = errors.New("response not cached")
+
+const (
+	_defaultSearchTTL = 30 * time.Minute
+	_defaultInfoTTL   = time.Hour
+)
+
+// CachedClient wraps a ClientInterface and caches its responses on disk,
+// one file per request. Expired responses are revalidated with
+// If-None-Match and If-Modified-Since when the wrapped client is a *Client.
+type CachedClient struct {
+	Client ClientInterface
+	Dir    string
+
+	// SearchTTL and InfoTTL are how long responses are served without
+	// asking the wrapped client.
+	SearchTTL time.Duration
+	InfoTTL   time.Duration
+
+	// Offline serves every request from the cache. Expired responses are
+	// returned with Pkg.Stale set.
+	Offline bool
+
+	now func() time.Time
+}
+
+// CacheOption allows setting custom parameters during construction.
+type CacheOption func(*CachedClient) error
+
+// NewCachedClient returns a client caching the responses of client in dir,
+// which is created if needed.
+func NewCachedClient(client ClientInterface, dir string, opts ...CacheOption) (*CachedClient, error) {
+	cached := CachedClient{
+		Client:    client,
+		Dir:       dir,
+		SearchTTL: _defaultSearchTTL,
+		InfoTTL:   _defaultInfoTTL,
+		now:       time.Now,
+	}
+
+	for _, o := range opts {
+		if err := o(&cached); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &cached, nil
+}
+
+// WithSearchTTL allows overriding how long Search responses are fresh.
+func WithSearchTTL(ttl time.Duration) CacheOption {
+	return func(c *CachedClient) error {
+		c.SearchTTL = ttl
+
+		return nil
+	}
+}
+
+// WithInfoTTL allows overriding how long Info responses are fresh.
+func WithInfoTTL(ttl time.Duration) CacheOption {
+	return func(c *CachedClient) error {
+		c.InfoTTL = ttl
+
+		return nil
+	}
+}
+
+// WithOffline allows serving every request from the cache.
+func WithOffline(offline bool) CacheOption {
+	return func(c *CachedClient) error {
+		c.Offline = offline
+
+		return nil
+	}
+}
+
+type cacheEntry struct {
+	Type         string    `json:"type"`
+	Args         []string  `json:"args"`
+	Fetched      time.Time `json:"fetched"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Results      []Pkg     `json:"results"`
+}
+
+// Search queries the AUR DB with an optional By field, through the cache.
+func (c *CachedClient) Search(ctx context.Context, query string, by By, reqEditors ...RequestEditorFn) ([]Pkg, error) {
+	return c.get(ctx, "search", []string{by.String(), query}, c.SearchTTL,
+		func(ctx context.Context, editors []RequestEditorFn) ([]Pkg, error) {
+			return c.Client.Search(ctx, query, by, editors...)
+		}, reqEditors)
+}
+
+// Info shows info for one or multiple packages, through the cache.
+func (c *CachedClient) Info(ctx context.Context, pkgs []string, reqEditors ...RequestEditorFn) ([]Pkg, error) {
+	return c.get(ctx, "info", pkgs, c.InfoTTL,
+		func(ctx context.Context, editors []RequestEditorFn) ([]Pkg, error) {
+			return c.Client.Info(ctx, pkgs, editors...)
+		}, reqEditors)
+}
+
+func (c *CachedClient) get(ctx context.Context, typ string, args []string, ttl time.Duration,
+	fetch func(context.Context, []RequestEditorFn) ([]Pkg, error), reqEditors []RequestEditorFn) ([]Pkg, error) {
+	file := c.path(typ, args)
+	entry, _ := readCacheEntry(file)
+	now := c.now()
+
+	if entry != nil && now.Sub(entry.Fetched) < ttl {
+		return entry.Results, nil
+	}
+
+	if c.Offline {
+		if entry == nil {
+			return nil, ErrNotCached
+		}
+
+		return staleResults(entry.Results), nil
+	}
+
+	rec := &validators{}
+	editors := reqEditors
+
+	if entry != nil && (entry.ETag != "" || entry.LastModified != "") {
+		editors = append([]RequestEditorFn{entry.revalidate}, reqEditors...)
+	}
+
+	results, err := fetch(context.WithValue(ctx, validatorsKey{}, rec), editors)
+
+	switch {
+	case entry != nil && errors.Is(err, ErrNotModified):
+		entry.Fetched = now
+		results = entry.Results
+	case err != nil:
+		return nil, err
+	default:
+		entry = &cacheEntry{Type: typ, Args: args, Fetched: now, Results: results}
+		entry.ETag, entry.LastModified = rec.get()
+	}
+
+	if err := writeCacheEntry(file, entry); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Invalidate removes the cached responses requesting or returning any of
+// the packages called names.
+func (c *CachedClient) Invalidate(names ...string) error {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		entry, err := readCacheEntry(file)
+		if err != nil {
+			continue
+		}
+
+		if !entry.mentions(names) {
+			continue
+		}
+
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to invalidate cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *CachedClient) path(typ string, args []string) string {
+	sum := sha256.Sum256([]byte(typ + "\x00" + strings.Join(args, "\x00")))
+
+	return filepath.Join(c.Dir, typ+"-"+hex.EncodeToString(sum[:])+".json")
+}
+
+func (e *cacheEntry) revalidate(ctx context.Context, req *http.Request) error {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+
+	return nil
+}
+
+func (e *cacheEntry) mentions(names []string) bool {
+	for _, name := range names {
+		if e.Type == "info" {
+			for _, arg := range e.Args {
+				if arg == name {
+					return true
+				}
+			}
+		}
+
+		for i := range e.Results {
+			if e.Results[i].Name == name || e.Results[i].PackageBase == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func readCacheEntry(file string) (*cacheEntry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := new(cacheEntry)
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("invalid cache entry %s: %w", file, err)
+	}
+
+	return entry, nil
+}
+
+// writeCacheEntry replaces file atomically, so that concurrent readers
+// never see a partial entry.
+func writeCacheEntry(file string, entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	return nil
+}
+
+func staleResults(results []Pkg) []Pkg {
+	stale := make([]Pkg, len(results))
+	copy(stale, results)
+
+	for i := range stale {
+		stale[i].Stale = true
+	}
+
+	return stale
+}
+
+type validatorsKey struct{}
+
+// validators collects the cache validators of the response to a request.
+// They are dropped when the request was split, as they would only apply to
+// one of the chunks.
+type validators struct {
+	mu           sync.Mutex
+	responses    int
+	etag         string
+	lastModified string
+}
+
+func (v *validators) get() (etag, lastModified string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.responses != 1 {
+		return "", ""
+	}
+
+	return v.etag, v.lastModified
+}
+
+func recordValidators(ctx context.Context, resp *http.Response) {
+	v, ok := ctx.Value(validatorsKey{}).(*validators)
+	if !ok {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.responses++
+	v.etag = resp.Header.Get("ETag")
+	v.lastModified = resp.Header.Get("Last-Modified")
+}
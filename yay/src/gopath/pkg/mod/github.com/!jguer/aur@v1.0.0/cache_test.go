@@ -0,0 +1,151 @@
+package aur
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type etagServer struct {
+	mu          sync.Mutex
+	requests    int
+	notModified int
+	etag        string
+}
+
+func (s *etagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	if r.Header.Get("If-None-Match") == s.etag {
+		s.notModified++
+
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	results := []Pkg{}
+	for _, name := range r.URL.Query()["arg[]"] {
+		results = append(results, Pkg{Name: name, Version: s.etag})
+	}
+
+	if arg := r.URL.Query().Get("arg"); arg != "" {
+		results = append(results, Pkg{Name: arg + "-git", PackageBase: arg, Version: s.etag})
+	}
+
+	payload, _ := json.Marshal(response{Version: 5, Type: "multiinfo", ResultCount: len(results), Results: results})
+
+	w.Header().Set("ETag", s.etag)
+	_, _ = w.Write(payload)
+}
+
+func newTestCachedClient(t *testing.T, s *etagServer, opts ...CacheOption) (*CachedClient, *time.Time) {
+	t.Helper()
+
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(WithBaseURL(server.URL))
+	assert.NoError(t, err)
+
+	cached, err := NewCachedClient(client, t.TempDir(), opts...)
+	assert.NoError(t, err)
+
+	now := time.Unix(1600000000, 0)
+	cached.now = func() time.Time { return now }
+
+	return cached, &now
+}
+
+func TestCachedClient_Info(t *testing.T) {
+	s := &etagServer{etag: `"1"`}
+	c, now := newTestCachedClient(t, s, WithInfoTTL(time.Minute))
+	ctx := context.Background()
+
+	got, err := c.Info(ctx, []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Pkg{{Name: "yay", Version: `"1"`}}, got)
+
+	got, err = c.Info(ctx, []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Pkg{{Name: "yay", Version: `"1"`}}, got)
+	assert.Equal(t, 1, s.requests)
+
+	// expired, revalidated with the ETag
+	*now = now.Add(2 * time.Minute)
+	got, err = c.Info(ctx, []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Pkg{{Name: "yay", Version: `"1"`}}, got)
+	assert.Equal(t, 2, s.requests)
+	assert.Equal(t, 1, s.notModified)
+
+	// revalidation refreshed the entry
+	_, err = c.Info(ctx, []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, s.requests)
+
+	*now = now.Add(2 * time.Minute)
+	s.etag = `"2"`
+	got, err = c.Info(ctx, []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Pkg{{Name: "yay", Version: `"2"`}}, got)
+	assert.Equal(t, 3, s.requests)
+}
+
+func TestCachedClient_Offline(t *testing.T) {
+	s := &etagServer{etag: `"1"`}
+	c, now := newTestCachedClient(t, s, WithSearchTTL(time.Minute))
+	ctx := context.Background()
+
+	_, err := c.Search(ctx, "yay", Name)
+	assert.NoError(t, err)
+
+	c.Offline = true
+
+	got, err := c.Search(ctx, "yay", Name)
+	assert.NoError(t, err)
+	assert.False(t, got[0].Stale)
+
+	*now = now.Add(2 * time.Minute)
+	got, err = c.Search(ctx, "yay", Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []Pkg{{Name: "yay-git", PackageBase: "yay", Version: `"1"`, Stale: true}}, got)
+
+	_, err = c.Search(ctx, "yay", Maintainer)
+	assert.ErrorIs(t, err, ErrNotCached)
+	assert.Equal(t, 1, s.requests)
+}
+
+func TestCachedClient_Invalidate(t *testing.T) {
+	s := &etagServer{etag: `"1"`}
+	c, _ := newTestCachedClient(t, s)
+	ctx := context.Background()
+
+	_, err := c.Info(ctx, []string{"yay", "paru"})
+	assert.NoError(t, err)
+	_, err = c.Info(ctx, []string{"auracle"})
+	assert.NoError(t, err)
+	_, err = c.Search(ctx, "yay", NameDesc)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Invalidate("yay"))
+
+	_, err = c.Info(ctx, []string{"auracle"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.requests)
+
+	_, err = c.Info(ctx, []string{"yay", "paru"})
+	assert.NoError(t, err)
+	_, err = c.Search(ctx, "yay", NameDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, s.requests)
+}
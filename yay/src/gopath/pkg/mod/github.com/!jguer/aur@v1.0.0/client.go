@@ -7,12 +7,29 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // ErrServiceUnavailable represents a error when AUR is unavailable.
 var ErrServiceUnavailable = errors.New("AUR is unavailable at this moment")
 
+// ErrNotModified is returned when a conditional request is answered with
+// 304 Not Modified.
+var ErrNotModified = errors.New("not modified")
+
+// ErrRateLimited is returned when the AUR refuses requests because too many
+// were made.
+var ErrRateLimited = errors.New("AUR rate limit reached")
+
+// ErrTooManyResults is returned when a search matches too many packages.
+var ErrTooManyResults = errors.New("too many package results")
+
+// ErrQueryTooShort is returned when a search term is too short.
+var ErrQueryTooShort = errors.New("query too short")
+
 type PayloadError struct {
 	StatusCode int
 	ErrorField string
@@ -22,6 +39,23 @@ func (r *PayloadError) Error() string {
 	return fmt.Sprintf("status %d: %s", r.StatusCode, r.ErrorField)
 }
 
+// Is reports whether the error reported by the AUR is target, one of
+// ErrRateLimited, ErrTooManyResults or ErrQueryTooShort.
+func (r *PayloadError) Is(target error) bool {
+	field := strings.ToLower(r.ErrorField)
+
+	switch target {
+	case ErrRateLimited:
+		return r.StatusCode == http.StatusTooManyRequests || strings.Contains(field, "rate limit")
+	case ErrTooManyResults:
+		return strings.Contains(field, "too many package results")
+	case ErrQueryTooShort:
+		return strings.Contains(field, "query arg too small")
+	}
+
+	return false
+}
+
 const _defaultURL = "https://aur.archlinux.org/rpc.php?"
 
 // ClientInterface specification for the AUR client.
@@ -45,6 +79,33 @@ type Client struct {
 	// A list of callbacks for modifying requests which are generated before sending over
 	// the network.
 	RequestEditors []RequestEditorFn
+
+	// MaxURILength bounds the length of the URLs of Info requests. Targets
+	// that do not fit in one URL are split into several requests.
+	// Defaults to the limit of the AUR when zero.
+	MaxURILength int
+
+	// MaxConcurrentRequests bounds the number of requests of a split Info
+	// running at the same time. Defaults to 4 when zero.
+	MaxConcurrentRequests int
+
+	// MaxRetries is how many times a request is retried when the AUR is
+	// unavailable or rate limited. Zero disables retries.
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the delay between retries, which
+	// doubles with each retry. Default to 500ms and 30s when zero.
+	// Requests are not retried when Retry-After exceeds MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// RPCVersion is the version of the RPC to use, 5 or 6. When zero, v6
+	// is tried first and v5 is used from then on if the server rejects it.
+	RPCVersion int
+
+	// negotiated is the version accepted by the server, when RPCVersion
+	// is zero.
+	negotiated int32
 }
 
 // ClientOption allows setting custom parameters during construction.
@@ -64,6 +125,7 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		BaseURL:        _defaultURL,
 		HTTPClient:     nil,
 		RequestEditors: []RequestEditorFn{},
+		MaxRetries:     _defaultMaxRetries,
 	}
 
 	// mutate client and add all optional params
@@ -110,6 +172,74 @@ func WithBaseURL(baseURL string) ClientOption {
 	}
 }
 
+// WithMaxURILength allows overriding the maximum length of Info request URLs.
+func WithMaxURILength(length int) ClientOption {
+	return func(c *Client) error {
+		if length <= 0 {
+			return fmt.Errorf("invalid max URI length: %d", length)
+		}
+
+		c.MaxURILength = length
+
+		return nil
+	}
+}
+
+// WithMaxConcurrentRequests allows overriding how many requests of a split
+// Info run at the same time.
+func WithMaxConcurrentRequests(n int) ClientOption {
+	return func(c *Client) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid max concurrent requests: %d", n)
+		}
+
+		c.MaxConcurrentRequests = n
+
+		return nil
+	}
+}
+
+// WithRPCVersion allows pinning the version of the RPC instead of
+// negotiating it.
+func WithRPCVersion(version int) ClientOption {
+	return func(c *Client) error {
+		if version != 5 && version != 6 {
+			return fmt.Errorf("unsupported RPC version: %d", version)
+		}
+
+		c.RPCVersion = version
+
+		return nil
+	}
+}
+
+// WithRetries allows overriding how many times requests are retried.
+func WithRetries(n int) ClientOption {
+	return func(c *Client) error {
+		if n < 0 {
+			return fmt.Errorf("invalid retries: %d", n)
+		}
+
+		c.MaxRetries = n
+
+		return nil
+	}
+}
+
+// WithBackoff allows overriding the bounds of the delay between retries.
+func WithBackoff(min, max time.Duration) ClientOption {
+	return func(c *Client) error {
+		if min <= 0 || max < min {
+			return fmt.Errorf("invalid backoff: %s-%s", min, max)
+		}
+
+		c.MinBackoff = min
+		c.MaxBackoff = max
+
+		return nil
+	}
+}
+
 // WithRequestEditorFn allows setting up a callback function, which will be
 // called right before sending the request. This can be used to mutate the request.
 func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
@@ -137,7 +267,9 @@ func (c *Client) applyEditors(ctx context.Context, req *http.Request, additional
 }
 
 func newAURRPCRequest(ctx context.Context, baseURL string, values url.Values) (*http.Request, error) {
-	values.Set("v", "5")
+	if values.Get("v") == "" {
+		values.Set("v", "5")
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+values.Encode(), nil)
 	if err != nil {
@@ -151,6 +283,10 @@ func getErrorByStatusCode(code int) error {
 	switch code {
 	case http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusServiceUnavailable:
 		return ErrServiceUnavailable
+	case http.StatusNotModified:
+		return ErrNotModified
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
 	}
 
 	return nil
@@ -181,7 +317,12 @@ func parseRPCResponse(resp *http.Response) ([]Pkg, error) {
 
 // Search queries the AUR DB with an optional By field.
 // Use By.None for default query param (name-desc).
+// The fields added in v6, such as Provides, fail on v5 servers.
 func (c *Client) Search(ctx context.Context, query string, by By, reqEditors ...RequestEditorFn) ([]Pkg, error) {
+	if by.sinceV6() && c.rpcVersion() == 5 {
+		return nil, fmt.Errorf("search by %s requires RPC v6", by)
+	}
+
 	v := url.Values{"type": []string{"search"}, "arg": []string{query}}
 
 	if by != None {
@@ -192,26 +333,113 @@ func (c *Client) Search(ctx context.Context, query string, by By, reqEditors ...
 }
 
 // Info shows info for one or multiple packages.
+// Targets that do not fit in one request URL are queried in chunks, see
+// MaxURILength. The results are in the order of pkgs. When some chunks
+// fail, the results of the others are returned with a *BatchError.
 func (c *Client) Info(ctx context.Context, pkgs []string, reqEditors ...RequestEditorFn) ([]Pkg, error) {
+	chunks := splitInfoTargets(c.BaseURL, pkgs, c.maxURILength())
+	if len(chunks) <= 1 {
+		results, err := c.info(ctx, pkgs, reqEditors)
+		if err != nil {
+			return nil, err
+		}
+
+		return sortByTargets(results, pkgs), nil
+	}
+
+	return c.infoBatch(ctx, pkgs, chunks, reqEditors)
+}
+
+func (c *Client) info(ctx context.Context, pkgs []string, reqEditors []RequestEditorFn) ([]Pkg, error) {
 	v := url.Values{"type": []string{"info"}, "arg[]": pkgs}
 
 	return c.get(ctx, v, reqEditors)
 }
 
 func (c *Client) get(ctx context.Context, values url.Values, reqEditors []RequestEditorFn) ([]Pkg, error) {
+	var results []Pkg
+
+	err := c.call(ctx, values, reqEditors, func(resp *http.Response) error {
+		var err error
+
+		results, err = parseRPCResponse(resp)
+
+		return err
+	})
+
+	return results, err
+}
+
+// call performs a request with parse, negotiating the version of the RPC
+// and retrying as configured.
+func (c *Client) call(ctx context.Context, values url.Values, reqEditors []RequestEditorFn,
+	parse func(*http.Response) error) error {
+	version := c.rpcVersion()
+
+	for attempt := 0; ; attempt++ {
+		values.Set("v", strconv.Itoa(version))
+
+		retryAfter, err := c.do(ctx, values, reqEditors, parse)
+		if err != nil && version == 6 && c.RPCVersion == 0 && isInvalidVersion(err) {
+			atomic.StoreInt32(&c.negotiated, 5)
+			version = 5
+			attempt--
+
+			continue
+		}
+
+		if err == nil || attempt >= c.MaxRetries || !isRetryable(err) {
+			return err
+		}
+
+		delay, ok := c.backoff(attempt, retryAfter)
+		if !ok || !c.wait(ctx, delay) {
+			return err
+		}
+	}
+}
+
+// do performs a single request. It also returns the delay requested by the
+// Retry-After header of the response, if any.
+func (c *Client) do(ctx context.Context, values url.Values, reqEditors []RequestEditorFn,
+	parse func(*http.Response) error) (time.Duration, error) {
 	req, err := newAURRPCRequest(ctx, c.BaseURL, values)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	if errApply := c.applyEditors(ctx, req, reqEditors); errApply != nil {
-		return nil, errApply
+		return 0, errApply
 	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	recordValidators(ctx, resp)
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	return retryAfter, parse(resp)
+}
+
+// rpcVersion returns the version of the RPC to request.
+func (c *Client) rpcVersion() int {
+	if c.RPCVersion != 0 {
+		return c.RPCVersion
 	}
 
-	return parseRPCResponse(resp)
+	if atomic.LoadInt32(&c.negotiated) == 5 {
+		return 5
+	}
+
+	return 6
+}
+
+// isInvalidVersion reports whether err is the refusal of the requested
+// version by a server not supporting it.
+func isInvalidVersion(err error) bool {
+	var payloadErr *PayloadError
+
+	return errors.As(err, &payloadErr) && strings.Contains(strings.ToLower(payloadErr.ErrorField), "invalid version")
 }
@@ -3,9 +3,13 @@ package aur
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -315,7 +319,7 @@ func TestClient_Search(t *testing.T) {
 	testClient.AssertExpectations(t)
 
 	requestMade := testClient.Calls[0].Arguments.Get(0).(*http.Request)
-	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg=test&by=name&type=search&v=5",
+	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg=test&by=name&type=search&v=6",
 		requestMade.URL.String())
 }
 
@@ -343,7 +347,7 @@ func TestClient_Info(t *testing.T) {
 	testClient.AssertExpectations(t)
 
 	requestMade := testClient.Calls[0].Arguments.Get(0).(*http.Request)
-	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg%5B%5D=test&type=info&v=5",
+	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg%5B%5D=test&type=info&v=6",
 		requestMade.URL.String())
 }
 
@@ -371,7 +375,7 @@ func TestClient_InfoNoMatch(t *testing.T) {
 	testClient.AssertExpectations(t)
 
 	requestMade := testClient.Calls[0].Arguments.Get(0).(*http.Request)
-	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg%5B%5D=test&type=info&v=5",
+	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg%5B%5D=test&type=info&v=6",
 		requestMade.URL.String())
 }
 
@@ -397,6 +401,147 @@ func TestClient_InfoError(t *testing.T) {
 	testClient.AssertExpectations(t)
 
 	requestMade := testClient.Calls[0].Arguments.Get(0).(*http.Request)
-	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg%5B%5D=test&type=info&v=5",
+	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg%5B%5D=test&type=info&v=6",
 		requestMade.URL.String())
 }
+
+// newInfoServer serves info requests with a package per requested name, in
+// reverse order, and records the requests. Requests for names in fail get a
+// 503.
+func newInfoServer(t *testing.T, fail ...string) (*httptest.Server, *[]*url.URL) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []*url.URL
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL)
+		mu.Unlock()
+
+		names := r.URL.Query()["arg[]"]
+		for _, name := range names {
+			for _, f := range fail {
+				if name == f {
+					w.WriteHeader(http.StatusServiceUnavailable)
+
+					return
+				}
+			}
+		}
+
+		results := []Pkg{}
+		for i := len(names) - 1; i >= 0; i-- {
+			results = append(results, Pkg{Name: names[i]})
+		}
+
+		payload, _ := json.Marshal(response{Version: 5, Type: "multiinfo", ResultCount: len(results), Results: results})
+		_, _ = w.Write(payload)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func infoTargets(n int) []string {
+	targets := make([]string, n)
+	for i := range targets {
+		targets[i] = fmt.Sprintf("package-%03d", i)
+	}
+
+	return targets
+}
+
+func TestClient_InfoBatch(t *testing.T) {
+	server, requests := newInfoServer(t)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithMaxURILength(200), WithMaxConcurrentRequests(2))
+	assert.NoError(t, err)
+
+	targets := infoTargets(30)
+	got, err := c.Info(context.Background(), targets)
+
+	assert.NoError(t, err)
+	assert.Greater(t, len(*requests), 1)
+
+	names := []string{}
+	for _, pkg := range got {
+		names = append(names, pkg.Name)
+	}
+
+	assert.Equal(t, targets, names)
+
+	count := 0
+	for _, request := range *requests {
+		assert.LessOrEqual(t, len(c.BaseURL)+len(request.RawQuery), 200)
+
+		count += len(request.Query()["arg[]"])
+	}
+
+	assert.Equal(t, len(targets), count)
+}
+
+func TestClient_InfoBatchPartialFailure(t *testing.T) {
+	server, _ := newInfoServer(t, "package-012")
+
+	c, err := NewClient(WithBaseURL(server.URL), WithMaxURILength(200), WithRetries(0))
+	assert.NoError(t, err)
+
+	targets := infoTargets(30)
+	got, err := c.Info(context.Background(), targets)
+
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+
+	var batchErr *BatchError
+
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Chunks, 1)
+	assert.Contains(t, batchErr.Chunks[0].Targets, "package-012")
+	assert.Len(t, got, len(targets)-len(batchErr.Chunks[0].Targets))
+	assert.NotContains(t, got, Pkg{Name: "package-012"})
+}
+
+func TestClient_InfoSingleRequest(t *testing.T) {
+	server, requests := newInfoServer(t)
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	assert.NoError(t, err)
+
+	got, err := c.Info(context.Background(), []string{"b", "a"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Pkg{{Name: "b"}, {Name: "a"}}, got)
+	assert.Len(t, *requests, 1)
+}
+
+func Test_splitInfoTargets(t *testing.T) {
+	baseURL := "https://aur.archlinux.org/rpc.php?"
+	fixed := len(baseURL) + len("type=info&v=6")
+
+	tests := []struct {
+		name      string
+		pkgs      []string
+		maxLength int
+		want      [][]string
+	}{
+		{name: "empty", pkgs: []string{}, maxLength: 100, want: [][]string{}},
+		{name: "fits", pkgs: []string{"a", "b"}, maxLength: 100, want: [][]string{{"a", "b"}}},
+		{
+			name: "split", pkgs: []string{"a", "b", "c"}, maxLength: fixed + 2*len("arg%5B%5D=a&"),
+			want: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name: "escaped", pkgs: []string{"a+", "b"}, maxLength: fixed + len("arg%5B%5D=a%2B&arg%5B%5D=b&") - 1,
+			want: [][]string{{"a+"}, {"b"}},
+		},
+		{name: "too long", pkgs: []string{"abc", "d"}, maxLength: 1, want: [][]string{{"abc"}, {"d"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitInfoTargets(baseURL, tt.pkgs, tt.maxLength))
+		})
+	}
+}
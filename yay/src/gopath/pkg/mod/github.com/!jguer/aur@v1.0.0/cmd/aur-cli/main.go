@@ -34,6 +34,20 @@ func getSearchBy(value string) aur.By {
 		return aur.OptDepends
 	case "checkdepends":
 		return aur.CheckDepends
+	case "provides":
+		return aur.Provides
+	case "conflicts":
+		return aur.Conflicts
+	case "replaces":
+		return aur.Replaces
+	case "keywords":
+		return aur.Keywords
+	case "groups":
+		return aur.Groups
+	case "submitter":
+		return aur.Submitter
+	case "comaintainers":
+		return aur.CoMaintainers
 	default:
 		return aur.NameDesc
 	}
@@ -41,7 +55,7 @@ func getSearchBy(value string) aur.By {
 
 func usage() {
 	fmt.Println("Usage:", os.Args[0], "<opts>", "<command>", "<pkg(s)>")
-	fmt.Println("Available commands:", "info, search")
+	fmt.Println("Available commands:", "info, search, suggest, suggest-pkgbase")
 	fmt.Println("Available opts:", "-by <Search for packages using a specified field>")
 
 	flag.Usage()
@@ -58,7 +72,8 @@ func main() {
 	)
 
 	flag.StringVar(&by, "by", "name-desc", "Search for packages using a specified field"+
-		"\n (name/name-desc/maintainer/depends/makedepends/optdepends/checkdepends)")
+		"\n (name/name-desc/maintainer/depends/makedepends/optdepends/checkdepends/"+
+		"\n provides/conflicts/replaces/keywords/groups/submitter/comaintainers)")
 	flag.StringVar(&aurURL, "url", "https://aur.archlinux.org/", "AUR URL")
 	flag.BoolVar(&verbose, "verbose", false, "display verbose information")
 	flag.BoolVar(&jsonDisplay, "json", false, "display result as JSON")
@@ -80,6 +95,12 @@ func main() {
 		fmt.Fprintln(os.Stderr, err)
 	}
 
+	if strings.HasPrefix(flag.Arg(0), "suggest") {
+		suggest(aurClient)
+
+		return
+	}
+
 	results, err := fn0(aurClient, by)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -121,6 +142,35 @@ func fn0(aurClient *aur.Client, by string) (results []aur.Pkg, err error) {
 	return results, err
 }
 
+// suggest prints the package or package base names starting with the
+// argument, one per line.
+func suggest(aurClient *aur.Client) {
+	var (
+		names []string
+		err   error
+	)
+
+	switch flag.Arg(0) {
+	case "suggest":
+		names, err = aurClient.Suggest(context.Background(), flag.Arg(1))
+	case "suggest-pkgbase":
+		names, err = aurClient.SuggestPkgbase(context.Background(), flag.Arg(1))
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("rpc request failed: %w", err))
+
+		os.Exit(1)
+	}
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
+
 func stylize(startCode, in string) string {
 	if UseColor {
 		return startCode + in + resetCode
@@ -0,0 +1,366 @@
+package aur
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+const _defaultMetadataURL = "https://aur.archlinux.org/packages-meta-ext-v1.json.gz"
+
+// MetadataClient answers queries from the metadata archive of the AUR,
+// which holds every package, instead of the RPC. The archive is read once
+// and all queries are local.
+type MetadataClient struct {
+	URL string
+
+	// Doer for downloading the archive, typically a *http.Client.
+	HTTPClient HTTPRequestDoer
+
+	// A list of callbacks for modifying the download request.
+	RequestEditors []RequestEditorFn
+
+	pkgs       []Pkg
+	byName     map[string]int
+	providers  map[string][]int
+	requiredBy map[string][]int
+}
+
+// MetadataOption allows setting custom parameters during construction.
+type MetadataOption func(*MetadataClient) error
+
+// WithMetadataURL allows overriding the URL of the metadata archive.
+func WithMetadataURL(url string) MetadataOption {
+	return func(c *MetadataClient) error {
+		c.URL = url
+
+		return nil
+	}
+}
+
+// WithMetadataHTTPClient allows overriding the default Doer.
+func WithMetadataHTTPClient(doer HTTPRequestDoer) MetadataOption {
+	return func(c *MetadataClient) error {
+		c.HTTPClient = doer
+
+		return nil
+	}
+}
+
+// WithMetadataRequestEditorFn adds a callback called right before
+// downloading the archive.
+func WithMetadataRequestEditorFn(fn RequestEditorFn) MetadataOption {
+	return func(c *MetadataClient) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+
+		return nil
+	}
+}
+
+// NewMetadataClient downloads the metadata archive and indexes it.
+func NewMetadataClient(ctx context.Context, opts ...MetadataOption) (*MetadataClient, error) {
+	client := MetadataClient{
+		URL:            _defaultMetadataURL,
+		HTTPClient:     http.DefaultClient,
+		RequestEditors: []RequestEditorFn{},
+	}
+
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", client.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for _, r := range client.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := getErrorByStatusCode(resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download metadata: status %d", resp.StatusCode)
+	}
+
+	if err := client.read(resp.Body); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// ReadMetadata reads a metadata archive, gzipped or not, from r.
+func ReadMetadata(r io.Reader) (*MetadataClient, error) {
+	client := MetadataClient{}
+	if err := client.read(r); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// LoadMetadataFile reads a metadata archive, gzipped or not, from file.
+func LoadMetadataFile(file string) (*MetadataClient, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadMetadata(f)
+}
+
+func (c *MetadataClient) read(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	var in io.Reader = br
+
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("metadata decoding failed: %w", err)
+		}
+		defer gz.Close()
+
+		in = gz
+	}
+
+	pkgs := []Pkg{}
+	if err := json.NewDecoder(in).Decode(&pkgs); err != nil {
+		return fmt.Errorf("metadata decoding failed: %w", err)
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].Name < pkgs[j].Name
+	})
+
+	c.pkgs = pkgs
+	c.index()
+
+	return nil
+}
+
+func (c *MetadataClient) index() {
+	c.byName = make(map[string]int, len(c.pkgs))
+	c.providers = map[string][]int{}
+	c.requiredBy = map[string][]int{}
+
+	for i := range c.pkgs {
+		pkg := &c.pkgs[i]
+		c.byName[pkg.Name] = i
+
+		for _, provide := range pkg.Provides {
+			name := depName(provide)
+			c.providers[name] = appendIndex(c.providers[name], i)
+		}
+
+		for _, deps := range [][]string{pkg.Depends, pkg.MakeDepends, pkg.CheckDepends, pkg.OptDepends} {
+			for _, dep := range deps {
+				name := depName(dep)
+				c.requiredBy[name] = appendIndex(c.requiredBy[name], i)
+			}
+		}
+	}
+}
+
+// appendIndex appends i unless it was just appended, as a package may list
+// the same dependency several times.
+func appendIndex(indexes []int, i int) []int {
+	if len(indexes) > 0 && indexes[len(indexes)-1] == i {
+		return indexes
+	}
+
+	return append(indexes, i)
+}
+
+// depName strips the version constraint and the description of a
+// dependency.
+func depName(dep string) string {
+	if i := strings.IndexAny(dep, "<>=:"); i >= 0 {
+		dep = dep[:i]
+	}
+
+	return strings.TrimSpace(dep)
+}
+
+// Len returns the number of packages in the archive.
+func (c *MetadataClient) Len() int {
+	return len(c.pkgs)
+}
+
+// Search queries the archive with an optional By field, like the RPC.
+// Name and description searches are case insensitive substring matches,
+// the others match whole names. Results are sorted by name.
+func (c *MetadataClient) Search(ctx context.Context, query string, by By, reqEditors ...RequestEditorFn) ([]Pkg, error) {
+	if by != Maintainer && by != Submitter && by != CoMaintainers && len(query) < 2 {
+		return nil, &PayloadError{StatusCode: http.StatusOK, ErrorField: "Query arg too small."}
+	}
+
+	match, err := searchMatcher(query, by)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []Pkg{}
+
+	for i := range c.pkgs {
+		if match(&c.pkgs[i]) {
+			results = append(results, c.pkgs[i])
+		}
+	}
+
+	return results, nil
+}
+
+func searchMatcher(query string, by By) (func(*Pkg) bool, error) {
+	lower := strings.ToLower(query)
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), lower)
+	}
+	hasDep := func(deps []string) bool {
+		for _, dep := range deps {
+			if depName(dep) == query {
+				return true
+			}
+		}
+
+		return false
+	}
+	has := func(values []string) bool {
+		for _, value := range values {
+			if strings.EqualFold(value, query) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch by {
+	case Name:
+		return func(pkg *Pkg) bool { return contains(pkg.Name) }, nil
+	case NameDesc, None:
+		return func(pkg *Pkg) bool { return contains(pkg.Name) || contains(pkg.Description) }, nil
+	case Maintainer:
+		return func(pkg *Pkg) bool { return pkg.Maintainer == query }, nil
+	case Depends:
+		return func(pkg *Pkg) bool { return hasDep(pkg.Depends) }, nil
+	case MakeDepends:
+		return func(pkg *Pkg) bool { return hasDep(pkg.MakeDepends) }, nil
+	case OptDepends:
+		return func(pkg *Pkg) bool { return hasDep(pkg.OptDepends) }, nil
+	case CheckDepends:
+		return func(pkg *Pkg) bool { return hasDep(pkg.CheckDepends) }, nil
+	case Provides:
+		return func(pkg *Pkg) bool { return pkg.Name == query || hasDep(pkg.Provides) }, nil
+	case Conflicts:
+		return func(pkg *Pkg) bool { return hasDep(pkg.Conflicts) }, nil
+	case Replaces:
+		return func(pkg *Pkg) bool { return hasDep(pkg.Replaces) }, nil
+	case Keywords:
+		return func(pkg *Pkg) bool { return has(pkg.Keywords) }, nil
+	case Groups:
+		return func(pkg *Pkg) bool { return has(pkg.Groups) }, nil
+	case Submitter:
+		return func(pkg *Pkg) bool { return pkg.Submitter == query }, nil
+	case CoMaintainers:
+		return func(pkg *Pkg) bool { return has(pkg.CoMaintainers) }, nil
+	}
+
+	return nil, fmt.Errorf("invalid search field: %d", by)
+}
+
+// Info shows info for one or multiple packages, in the order of pkgs.
+// Unknown packages are skipped.
+func (c *MetadataClient) Info(ctx context.Context, pkgs []string, reqEditors ...RequestEditorFn) ([]Pkg, error) {
+	results := []Pkg{}
+	seen := make(map[string]bool, len(pkgs))
+
+	for _, name := range pkgs {
+		i, ok := c.byName[name]
+		if !ok || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+
+		results = append(results, c.pkgs[i])
+	}
+
+	return results, nil
+}
+
+// ReverseDepends returns the packages depending on name, at build, check,
+// run time or optionally.
+func (c *MetadataClient) ReverseDepends(name string) []Pkg {
+	return c.collect(c.requiredBy[name])
+}
+
+// Providers returns the package called name, if any, followed by the
+// packages providing name.
+func (c *MetadataClient) Providers(name string) []Pkg {
+	results := []Pkg{}
+
+	i, ok := c.byName[name]
+	if ok {
+		results = append(results, c.pkgs[i])
+	}
+
+	for _, j := range c.providers[name] {
+		if !ok || j != i {
+			results = append(results, c.pkgs[j])
+		}
+	}
+
+	return results
+}
+
+// MaintainedBy returns the packages maintained by any of users. An empty
+// user matches orphaned packages.
+func (c *MetadataClient) MaintainedBy(users ...string) []Pkg {
+	wanted := make(map[string]bool, len(users))
+	for _, user := range users {
+		wanted[user] = true
+	}
+
+	results := []Pkg{}
+
+	for i := range c.pkgs {
+		if wanted[c.pkgs[i].Maintainer] {
+			results = append(results, c.pkgs[i])
+		}
+	}
+
+	return results
+}
+
+func (c *MetadataClient) collect(indexes []int) []Pkg {
+	results := make([]Pkg, 0, len(indexes))
+	for _, i := range indexes {
+		results = append(results, c.pkgs[i])
+	}
+
+	return results
+}
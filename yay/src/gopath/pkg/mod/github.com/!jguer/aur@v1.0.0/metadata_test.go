@@ -0,0 +1,123 @@
+package aur
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const metadataFixture = "testdata/packages-meta-ext-v1.json.gz"
+
+func pkgNames(pkgs []Pkg) []string {
+	names := []string{}
+	for i := range pkgs {
+		names = append(names, pkgs[i].Name)
+	}
+
+	return names
+}
+
+func TestNewMetadataClient(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		requests++
+
+		assert.Equal(t, "aur-test", r.Header.Get("User-Agent"))
+		http.ServeFile(w, r, metadataFixture)
+	}))
+	defer server.Close()
+
+	c, err := NewMetadataClient(context.Background(), WithMetadataURL(server.URL),
+		WithMetadataRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("User-Agent", "aur-test")
+
+			return nil
+		}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, c.Len())
+
+	_, err = c.Info(context.Background(), []string{"yay"})
+	assert.NoError(t, err)
+	_, err = c.Search(context.Background(), "yay", Name)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	_, err = NewMetadataClient(context.Background(), WithMetadataURL(server.URL+"/missing"))
+	assert.Error(t, err)
+}
+
+func TestMetadataClient_Search(t *testing.T) {
+	c, err := LoadMetadataFile(metadataFixture)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		query string
+		by    By
+		want  []string
+	}{
+		{query: "YAY", by: Name, want: []string{"yay", "yay-bin"}},
+		{query: "aur helper", by: NameDesc, want: []string{"paru", "yay", "yay-bin"}},
+		{query: "aur agent", by: None, want: []string{"cower"}},
+		{query: "jguer", by: Maintainer, want: []string{"yay", "yay-bin"}},
+		{query: "", by: Maintainer, want: []string{"cower"}},
+		{query: "pacman", by: Depends, want: []string{"cower", "paru", "yay", "yay-bin"}},
+		{query: "go", by: MakeDepends, want: []string{"yay"}},
+		{query: "sudo", by: OptDepends, want: []string{"yay", "yay-bin"}},
+		{query: "git", by: CheckDepends, want: []string{"paru"}},
+		{query: "pac", by: Depends, want: []string{}},
+		{query: "yay", by: Provides, want: []string{"yay", "yay-bin"}},
+		{query: "yay", by: Conflicts, want: []string{"yay-bin"}},
+		{query: "binary", by: Keywords, want: []string{"yay-bin"}},
+		{query: "aur-helpers", by: Groups, want: []string{"paru"}},
+		{query: "jguer", by: Submitter, want: []string{"yay", "yay-bin"}},
+		{query: "Morganamilo", by: CoMaintainers, want: []string{"yay"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.by.String()+"/"+tt.query, func(t *testing.T) {
+			got, err := c.Search(context.Background(), tt.query, tt.by)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, pkgNames(got))
+		})
+	}
+
+	_, err = c.Search(context.Background(), "y", Name)
+
+	var payloadErr *PayloadError
+
+	assert.ErrorAs(t, err, &payloadErr)
+	assert.ErrorIs(t, err, ErrQueryTooShort)
+}
+
+func TestMetadataClient_Info(t *testing.T) {
+	c, err := LoadMetadataFile(metadataFixture)
+	assert.NoError(t, err)
+
+	got, err := c.Info(context.Background(), []string{"paru", "missing", "cower", "paru"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"paru", "cower"}, pkgNames(got))
+	assert.Equal(t, "18-1", got[1].Version)
+	assert.Equal(t, 1540000000, got[1].OutOfDate)
+	assert.Equal(t, []string{"bat: colored pkgbuild printing", "devtools: build in chroot"}, got[0].OptDepends)
+}
+
+func TestMetadataClient_Queries(t *testing.T) {
+	c, err := LoadMetadataFile(metadataFixture)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"paru", "yay", "yay-bin"}, pkgNames(c.ReverseDepends("git")))
+	assert.Equal(t, []string{"paru"}, pkgNames(c.ReverseDepends("devtools")))
+	assert.Equal(t, []string{"yay", "yay-bin"}, pkgNames(c.Providers("yay")))
+	assert.Equal(t, []string{"yay-bin"}, pkgNames(c.Providers("yay-bin")))
+	assert.Equal(t, []string{}, pkgNames(c.Providers("missing")))
+	assert.Equal(t, []string{"cower", "paru"}, pkgNames(c.MaintainedBy("Morganamilo", "")))
+}
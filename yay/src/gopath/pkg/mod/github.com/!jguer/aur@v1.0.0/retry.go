@@ -0,0 +1,104 @@
+package aur
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	_defaultMaxRetries = 3
+	_defaultMinBackoff = 500 * time.Millisecond
+	_defaultMaxBackoff = 30 * time.Second
+)
+
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrServiceUnavailable) || errors.Is(err, ErrRateLimited)
+}
+
+// backoff returns the delay before retry number attempt. Retry-After is
+// followed when set, otherwise the delay doubles with each attempt, with
+// jitter so that concurrent clients do not retry in lockstep. It returns
+// false when Retry-After asks to wait longer than MaxBackoff, in which case
+// the request should not be retried.
+func (c *Client) backoff(attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	max := c.maxBackoff()
+	if retryAfter > max {
+		return 0, false
+	}
+
+	if retryAfter > 0 {
+		return retryAfter, true
+	}
+
+	min := c.MinBackoff
+	if min <= 0 {
+		min = _defaultMinBackoff
+	}
+
+	delay := min
+	for i := 0; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		delay = max
+	}
+
+	// between half and all of the delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)), true // nolint:gosec
+}
+
+func (c *Client) maxBackoff() time.Duration {
+	if c.MaxBackoff > 0 {
+		return c.MaxBackoff
+	}
+
+	return _defaultMaxBackoff
+}
+
+// wait sleeps for delay, unless the context ends first or would end before
+// the delay, in which case it returns false right away.
+func (c *Client) wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, either a number of seconds
+// or an HTTP date. It returns zero when the header is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+
+	return date.Sub(now)
+}
@@ -0,0 +1,159 @@
+package aur
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFlakyServer answers the first failures requests with status and
+// retryAfter, then succeeds.
+func newFlakyServer(t *testing.T, failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"version":5,"type":"error","resultcount":0,"results":[],"error":"Rate limit reached"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(validPayload))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		failures     int32
+		retries      int
+		retryAfter   string
+		wantErr      error
+		wantRequests int32
+	}{
+		{name: "unavailable", status: http.StatusServiceUnavailable, failures: 2, retries: 3, wantRequests: 3},
+		{name: "rate limited", status: http.StatusTooManyRequests, failures: 1, retries: 3, wantRequests: 2},
+		{
+			name: "exhausted", status: http.StatusBadGateway, failures: 5, retries: 2,
+			wantErr: ErrServiceUnavailable, wantRequests: 3,
+		},
+		{
+			name: "disabled", status: http.StatusTooManyRequests, failures: 1, retries: 0,
+			wantErr: ErrRateLimited, wantRequests: 1,
+		},
+		{
+			name: "retry after too long", status: http.StatusTooManyRequests, failures: 1, retries: 3,
+			retryAfter: "3600", wantErr: ErrRateLimited, wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newFlakyServer(t, tt.failures, tt.status, tt.retryAfter)
+
+			c, err := NewClient(WithBaseURL(server.URL), WithRetries(tt.retries),
+				WithBackoff(time.Millisecond, 5*time.Millisecond))
+			assert.NoError(t, err)
+
+			got, err := c.Info(context.Background(), []string{"cower"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, validPayloadItems, got)
+			}
+
+			assert.Equal(t, tt.wantRequests, atomic.LoadInt32(requests))
+		})
+	}
+}
+
+func TestClient_RetryAfterDeadline(t *testing.T) {
+	server, requests := newFlakyServer(t, 1, http.StatusTooManyRequests, "120")
+
+	c, err := NewClient(WithBaseURL(server.URL), WithBackoff(time.Millisecond, 5*time.Millisecond))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.Search(ctx, "cower", Name)
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func TestClient_backoff(t *testing.T) {
+	c := &Client{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		want *= time.Millisecond
+		got, ok := c.backoff(attempt, 0)
+
+		assert.True(t, ok)
+
+		assert.GreaterOrEqual(t, got, want/2)
+		assert.LessOrEqual(t, got, want)
+	}
+
+	got, ok := c.backoff(0, 500*time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, got)
+
+	_, ok = c.backoff(0, 3*time.Second)
+	assert.False(t, ok)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-1", 0},
+		{"Tue, 01 Jun 2021 12:01:00 GMT", time.Minute},
+		{"Tue, 01 Jun 2021 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseRetryAfter(tt.value, now), tt.value)
+	}
+}
+
+func TestPayloadError_Is(t *testing.T) {
+	tests := []struct {
+		err  *PayloadError
+		want error
+	}{
+		{&PayloadError{StatusCode: 200, ErrorField: "Too many package results."}, ErrTooManyResults},
+		{&PayloadError{StatusCode: 200, ErrorField: "Query arg too small."}, ErrQueryTooShort},
+		{&PayloadError{StatusCode: 200, ErrorField: "Rate limit reached"}, ErrRateLimited},
+		{&PayloadError{StatusCode: 429, ErrorField: ""}, ErrRateLimited},
+	}
+	for _, tt := range tests {
+		assert.ErrorIs(t, tt.err, tt.want, tt.err.ErrorField)
+	}
+
+	assert.NotErrorIs(t, &PayloadError{StatusCode: 200, ErrorField: "Incorrect by field specified."}, ErrQueryTooShort)
+}
@@ -0,0 +1,73 @@
+package aur
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// Suggest returns the names of the packages starting with prefix, as used
+// by the search box of the AUR. It is meant for shell completion.
+func (c *Client) Suggest(ctx context.Context, prefix string, reqEditors ...RequestEditorFn) ([]string, error) {
+	return c.suggest(ctx, "suggest", prefix, reqEditors)
+}
+
+// SuggestPkgbase returns the names of the package bases starting with
+// prefix.
+func (c *Client) SuggestPkgbase(ctx context.Context, prefix string, reqEditors ...RequestEditorFn) ([]string, error) {
+	return c.suggest(ctx, "suggest-pkgbase", prefix, reqEditors)
+}
+
+func (c *Client) suggest(ctx context.Context, typ, prefix string, reqEditors []RequestEditorFn) ([]string, error) {
+	v := url.Values{"type": []string{typ}, "arg": []string{prefix}}
+
+	var names []string
+
+	err := c.call(ctx, v, reqEditors, func(resp *http.Response) error {
+		var err error
+
+		names, err = parseSuggestResponse(resp)
+
+		return err
+	})
+
+	return names, err
+}
+
+// parseSuggestResponse parses the list of names of a suggest response.
+// Errors are reported in the usual response object instead.
+func parseSuggestResponse(resp *http.Response) ([]string, error) {
+	defer resp.Body.Close()
+
+	if err := getErrorByStatusCode(resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("response decoding failed: %w", err)
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		_, err := parseRPCResponse(&http.Response{
+			StatusCode: resp.StatusCode,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		})
+		if err == nil {
+			err = fmt.Errorf("response decoding failed: unexpected suggest response")
+		}
+
+		return nil, err
+	}
+
+	names := []string{}
+	if err := json.Unmarshal(body, &names); err != nil {
+		return nil, fmt.Errorf("response decoding failed: %w", err)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,171 @@
+package aur
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rpcServer is a stand-in for the RPC of the AUR, supporting versions up
+// to maxVersion.
+type rpcServer struct {
+	maxVersion int
+
+	mu       sync.Mutex
+	versions []string
+}
+
+func (s *rpcServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	s.versions = append(s.versions, query.Get("v"))
+	s.mu.Unlock()
+
+	writeError := func(msg string) {
+		payload, _ := json.Marshal(response{Type: "error", Error: msg, Results: []Pkg{}})
+		_, _ = w.Write(payload)
+	}
+
+	if v, err := strconv.Atoi(query.Get("v")); err != nil || v < 5 || v > s.maxVersion {
+		writeError("Invalid version specified.")
+
+		return
+	}
+
+	pkgs := []Pkg{
+		{Name: "yay", PackageBase: "yay", Provides: []string{"yay"}},
+		{Name: "yay-bin", PackageBase: "yay-bin", Provides: []string{"yay"}},
+		{Name: "python-yaml", PackageBase: "python-yaml"},
+	}
+	if s.maxVersion >= 6 {
+		pkgs[0].Submitter, pkgs[0].CoMaintainers = "jguer", []string{"morganamilo"}
+	}
+
+	switch query.Get("type") {
+	case "suggest", "suggest-pkgbase":
+		names := []string{}
+		for _, pkg := range pkgs {
+			if strings.HasPrefix(pkg.Name, query.Get("arg")) {
+				names = append(names, pkg.Name)
+			}
+		}
+
+		payload, _ := json.Marshal(names)
+		_, _ = w.Write(payload)
+	case "search":
+		if query.Get("by") != "provides" {
+			writeError("Incorrect by field specified.")
+
+			return
+		}
+
+		results := []Pkg{}
+		for _, pkg := range pkgs {
+			if len(pkg.Provides) > 0 && pkg.Provides[0] == query.Get("arg") {
+				results = append(results, pkg)
+			}
+		}
+
+		payload, _ := json.Marshal(response{Type: "search", ResultCount: len(results), Results: results})
+		_, _ = w.Write(payload)
+	case "info":
+		payload, _ := json.Marshal(response{Type: "multiinfo", ResultCount: 1, Results: pkgs[:1]})
+		_, _ = w.Write(payload)
+	default:
+		writeError("Incorrect request type specified.")
+	}
+}
+
+func newRPCTestClient(t *testing.T, maxVersion int, opts ...ClientOption) (*Client, *rpcServer) {
+	t.Helper()
+
+	s := &rpcServer{maxVersion: maxVersion}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(append([]ClientOption{WithBaseURL(server.URL)}, opts...)...)
+	assert.NoError(t, err)
+
+	return c, s
+}
+
+func TestClient_Suggest(t *testing.T) {
+	c, s := newRPCTestClient(t, 6)
+
+	got, err := c.Suggest(context.Background(), "yay")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"yay", "yay-bin"}, got)
+
+	got, err = c.SuggestPkgbase(context.Background(), "python")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"python-yaml"}, got)
+
+	got, err = c.Suggest(context.Background(), "zzz")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, got)
+
+	assert.Equal(t, []string{"6", "6", "6"}, s.versions)
+}
+
+func TestClient_SuggestError(t *testing.T) {
+	c, _ := newRPCTestClient(t, 5, WithRPCVersion(6))
+
+	_, err := c.Suggest(context.Background(), "yay")
+
+	var payloadErr *PayloadError
+
+	assert.ErrorAs(t, err, &payloadErr)
+	assert.Equal(t, "Invalid version specified.", payloadErr.ErrorField)
+}
+
+func TestClient_NegotiateV6(t *testing.T) {
+	c, s := newRPCTestClient(t, 6)
+
+	got, err := c.Info(context.Background(), []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, "jguer", got[0].Submitter)
+	assert.Equal(t, []string{"morganamilo"}, got[0].CoMaintainers)
+
+	got, err = c.Search(context.Background(), "yay", Provides)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	assert.Equal(t, []string{"6", "6"}, s.versions)
+}
+
+func TestClient_NegotiateV5(t *testing.T) {
+	c, s := newRPCTestClient(t, 5)
+
+	got, err := c.Info(context.Background(), []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, "yay", got[0].Name)
+	assert.Empty(t, got[0].Submitter)
+
+	_, err = c.Suggest(context.Background(), "yay")
+	assert.NoError(t, err)
+
+	// v6 only fields are refused once v5 is negotiated
+	_, err = c.Search(context.Background(), "yay", Provides)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"6", "5", "5"}, s.versions)
+}
+
+func TestClient_PinnedVersion(t *testing.T) {
+	c, s := newRPCTestClient(t, 6, WithRPCVersion(5))
+
+	_, err := c.Info(context.Background(), []string{"yay"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5"}, s.versions)
+
+	_, err = NewClient(WithRPCVersion(7))
+	assert.Error(t, err)
+}
@@ -34,6 +34,13 @@ type Pkg struct {
 	Groups         []string `json:"Groups"`
 	License        []string `json:"License"`
 	Keywords       []string `json:"Keywords"`
+	// CoMaintainers and Submitter may be empty with v5 servers.
+	CoMaintainers []string `json:"CoMaintainers"`
+	Submitter     string   `json:"Submitter"`
+
+	// Stale is set on packages served by an offline CachedClient from an
+	// expired response.
+	Stale bool `json:"-"`
 }
 
 // By specifies what to search by in RPC searches.
@@ -48,6 +55,14 @@ const (
 	OptDepends
 	CheckDepends
 	None
+	// Fields available since RPC v6.
+	Provides
+	Conflicts
+	Replaces
+	Keywords
+	Groups
+	Submitter
+	CoMaintainers
 )
 
 func (by By) String() string {
@@ -68,7 +83,25 @@ func (by By) String() string {
 		return "checkdepends"
 	case None:
 		return ""
+	case Provides:
+		return "provides"
+	case Conflicts:
+		return "conflicts"
+	case Replaces:
+		return "replaces"
+	case Keywords:
+		return "keywords"
+	case Groups:
+		return "groups"
+	case Submitter:
+		return "submitter"
+	case CoMaintainers:
+		return "comaintainers"
 	default:
 		panic("invalid By")
 	}
 }
+
+func (by By) sinceV6() bool {
+	return by >= Provides && by <= CoMaintainers
+}
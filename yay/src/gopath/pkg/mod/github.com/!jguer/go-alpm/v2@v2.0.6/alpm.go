@@ -28,11 +28,18 @@ func Initialize(root, dbpath string) (*Handle, error) {
 	return &Handle{h}, nil
 }
 
-// Release releases the alpm handle
+// Release releases the alpm handle and the callbacks registered on it.
 func (h *Handle) Release() error {
+	token, registered := detachCallbacks(h.ptr)
 	if er := C.alpm_release(h.ptr); er != 0 {
+		if registered {
+			attachCallbacks(h.ptr, token)
+		}
 		return Error(er)
 	}
+	if registered {
+		unregisterCallbacks(token)
+	}
 	h.ptr = nil
 	return nil
 }
@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 // alpm_test.go - Tests for alpm.go.
 //
 // Copyright (c) 2013 The go-alpm Authors
@@ -0,0 +1,189 @@
+// alpmtest_test.go - Tests for the in-memory implementations.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpmtest
+
+import (
+	"reflect"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+)
+
+func testDBs() (*DB, *DBList) {
+	local := NewLocalDB(
+		NewPackage("pacman", "6.0.0-1").WithDepends("glibc>=2.33", "sh").WithOptionalDepends("git: for VCS"),
+		NewPackage("glibc", "2.33-5"),
+		NewPackage("bash", "5.1.008-1").WithProvides("sh"),
+		NewPackage("git", "2.32.0-1").WithReason(alpm.PkgReasonDepend),
+	)
+
+	core := NewDB("core",
+		NewPackage("pacman", "6.0.1-1").WithGroups("base-devel").WithDescription("A library-based package manager"),
+		NewPackage("glibc", "2.33-5").WithSize(10, 40),
+		NewPackage("bash", "5.1.008-1").WithProvides("sh").WithSize(10, 8),
+		NewPackage("make", "4.3-3").WithGroups("base-devel").WithSize(1, 2),
+		NewPackage("sed", "4.8-1").WithGroups("base-devel").WithIgnore(true),
+	)
+	extra := NewDB("extra",
+		NewPackage("make", "4.4-1").WithGroups("base-devel"),
+		NewPackage("zsh", "5.8-1").WithProvides("sh=5.8"),
+		NewPackage("git", "2.33.0-1").WithDepends("sh"),
+	)
+
+	return local, NewDBList(core, extra)
+}
+
+func names(l alpm.IPackageList) []string {
+	names := []string{}
+	_ = l.ForEach(func(pkg alpm.IPackage) error {
+		names = append(names, pkg.Name()+"-"+pkg.Version())
+		return nil
+	})
+	return names
+}
+
+func TestPackage(t *testing.T) {
+	pkg := NewPackage("yay", "11.0.0-1").
+		WithDepends("pacman>=6", "git").
+		WithGroups("aur").
+		WithLicenses("GPL3").
+		WithBackup(alpm.BackupFile{Name: "etc/yay.conf", Hash: "abc"}).
+		WithFiles(alpm.File{Name: "usr/bin/yay", Size: 10})
+
+	if got := pkg.Depends().Slice(); len(got) != 2 || got[0].String() != "pacman>=6" || got[1].Mod != alpm.DepModAny {
+		t.Errorf("unexpected depends %v", got)
+	}
+	if got := pkg.Groups().Slice(); !reflect.DeepEqual(got, []string{"aur"}) {
+		t.Errorf("unexpected groups %v", got)
+	}
+	if got := pkg.Backup().Slice(); len(got) != 1 || got[0].Name != "etc/yay.conf" {
+		t.Errorf("unexpected backup %v", got)
+	}
+	if !pkg.Conflicts().Empty() {
+		t.Errorf("conflicts are not empty")
+	}
+	if _, err := pkg.ContainsFile("usr/bin/yay"); err != nil {
+		t.Errorf("ContainsFile failed: %s", err)
+	}
+	if pkg.Origin() != alpm.FromFile || pkg.DB() != nil {
+		t.Errorf("package without database has origin %d", pkg.Origin())
+	}
+	if pkg.FileName() != "yay-11.0.0-1-any.pkg.tar.zst" {
+		t.Errorf("unexpected file name %s", pkg.FileName())
+	}
+}
+
+func TestComputeRequiredBy(t *testing.T) {
+	local, dbs := testDBs()
+
+	if got := local.Pkg("glibc").ComputeRequiredBy(); !reflect.DeepEqual(got, []string{"pacman"}) {
+		t.Errorf("glibc is required by %v", got)
+	}
+	if got := local.Pkg("bash").ComputeRequiredBy(); !reflect.DeepEqual(got, []string{"pacman"}) {
+		t.Errorf("bash is required by %v", got)
+	}
+	if got := local.Pkg("git").ComputeOptionalFor(); !reflect.DeepEqual(got, []string{"pacman"}) {
+		t.Errorf("git is optional for %v", got)
+	}
+
+	bash := dbs.Slice()[0].Pkg("bash")
+	if got := bash.ComputeRequiredBy(); !reflect.DeepEqual(got, []string{"git"}) {
+		t.Errorf("sync bash is required by %v", got)
+	}
+}
+
+func TestDBListFindSatisfier(t *testing.T) {
+	_, dbs := testDBs()
+
+	tests := []struct {
+		dep  string
+		want string
+	}{
+		{"pacman", "pacman-6.0.1-1"},
+		{"make>4.3", "make-4.4-1"},
+		{"sh", "bash-5.1.008-1"},
+		{"sh>=5", "zsh-5.8-1"},
+		{"sed", ""},
+		{"pacman>7", ""},
+	}
+
+	for _, tt := range tests {
+		pkg, err := dbs.FindSatisfier(tt.dep)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("%s: got %s, want an error", tt.dep, pkg.Name())
+		case tt.want != "" && err != nil:
+			t.Errorf("%s: %s", tt.dep, err)
+		case tt.want != "" && pkg.Name()+"-"+pkg.Version() != tt.want:
+			t.Errorf("%s: got %s-%s, want %s", tt.dep, pkg.Name(), pkg.Version(), tt.want)
+		}
+	}
+
+	dbs.Slice()[0].SetUsage(alpm.UsageSearch)
+	if pkg, err := dbs.FindSatisfier("make"); err != nil || pkg.Version() != "4.4-1" {
+		t.Errorf("database without install usage was used")
+	}
+}
+
+func TestFindGroupPkgs(t *testing.T) {
+	_, dbs := testDBs()
+
+	got := names(dbs.FindGroupPkgs("base-devel"))
+	want := []string{"pacman-6.0.1-1", "make-4.3-3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSearchAndSort(t *testing.T) {
+	_, dbs := testDBs()
+	core := dbs.Slice()[0]
+
+	if got := names(core.Search([]string{"PACKAGE", "^pac"})); !reflect.DeepEqual(got, []string{"pacman-6.0.1-1"}) {
+		t.Errorf("search returned %v", got)
+	}
+	if got := names(core.Search([]string{"^sh$"})); !reflect.DeepEqual(got, []string{"bash-5.1.008-1"}) {
+		t.Errorf("search on provides returned %v", got)
+	}
+	if got := names(core.Search([]string{"("})); len(got) != 0 {
+		t.Errorf("invalid search returned %v", got)
+	}
+
+	got := names(core.PkgCache().SortBySize())
+	if got[0] != "glibc-2.33-5" || got[1] != "bash-5.1.008-1" {
+		t.Errorf("unexpected order %v", got)
+	}
+}
+
+func TestSyncNewVersion(t *testing.T) {
+	local, dbs := testDBs()
+
+	if pkg := local.Pkg("pacman").SyncNewVersion(dbs); pkg == nil || pkg.Version() != "6.0.1-1" {
+		t.Errorf("pacman upgrade not found")
+	}
+	if pkg := local.Pkg("glibc").SyncNewVersion(dbs); pkg != nil {
+		t.Errorf("glibc is up to date but got %s", pkg.Version())
+	}
+	if pkg := local.Pkg("git").SyncNewVersion(dbs); pkg == nil || pkg.DB().Name() != "extra" {
+		t.Errorf("git upgrade not found in extra")
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	_, dbs := testDBs()
+	core := dbs.Slice()[0]
+
+	if err := core.Unregister(); err != nil {
+		t.Fatalf("Unregister failed: %s", err)
+	}
+	if len(dbs.Slice()) != 1 {
+		t.Errorf("database still in the list")
+	}
+	if err := core.Unregister(); err == nil {
+		t.Errorf("second Unregister should fail")
+	}
+}
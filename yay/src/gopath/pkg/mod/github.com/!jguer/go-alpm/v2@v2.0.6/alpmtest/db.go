@@ -0,0 +1,302 @@
+// db.go - In-memory databases and lists.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpmtest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+)
+
+// DB is an in-memory alpm.IDB.
+type DB struct {
+	name    string
+	local   bool
+	servers []string
+	usage   alpm.Usage
+	pkgs    []*Package
+
+	sigs   alpm.SigList
+	sigErr error
+
+	list *DBList
+}
+
+var _ alpm.IDB = (*DB)(nil)
+
+// NewDB returns a sync database holding pkgs.
+func NewDB(name string, pkgs ...*Package) *DB {
+	db := &DB{name: name, usage: alpm.UsageAll}
+	return db.WithPackages(pkgs...)
+}
+
+// NewLocalDB returns a local database holding pkgs.
+func NewLocalDB(pkgs ...*Package) *DB {
+	db := &DB{name: "local", local: true, usage: alpm.UsageAll}
+	return db.WithPackages(pkgs...)
+}
+
+// WithPackages adds pkgs to the database.
+func (db *DB) WithPackages(pkgs ...*Package) *DB {
+	for _, pkg := range pkgs {
+		pkg.db = db
+	}
+	db.pkgs = append(db.pkgs, pkgs...)
+	return db
+}
+
+// WithSignature sets the result returned by CheckPGPSignature.
+func (db *DB) WithSignature(sigs alpm.SigList, err error) *DB {
+	db.sigs, db.sigErr = sigs, err
+	return db
+}
+
+// Unregister removes the database from the list it belongs to.
+func (db *DB) Unregister() error {
+	if db.list == nil {
+		return fmt.Errorf("database %s is not registered", db.name)
+	}
+
+	dbs := db.list.dbs[:0]
+	for _, d := range db.list.dbs {
+		if d != db {
+			dbs = append(dbs, d)
+		}
+	}
+	db.list.dbs = dbs
+	db.list = nil
+
+	return nil
+}
+
+func (db *DB) Name() string {
+	return db.name
+}
+
+func (db *DB) Servers() []string {
+	return db.servers
+}
+
+func (db *DB) SetServers(servers []string) {
+	db.servers = servers
+}
+
+func (db *DB) AddServer(server string) {
+	db.servers = append(db.servers, server)
+}
+
+func (db *DB) SetUsage(usage alpm.Usage) {
+	db.usage = usage
+}
+
+// Usage returns the usage set with SetUsage, UsageAll by default.
+func (db *DB) Usage() alpm.Usage {
+	return db.usage
+}
+
+func (db *DB) Pkg(name string) alpm.IPackage {
+	for _, pkg := range db.pkgs {
+		if pkg.name == name {
+			return pkg
+		}
+	}
+	return nil
+}
+
+func (db *DB) PkgCache() alpm.IPackageList {
+	l := make(PackageList, len(db.pkgs))
+	for i, pkg := range db.pkgs {
+		l[i] = pkg
+	}
+	return l
+}
+
+// Search returns the packages matching every target, which are case
+// insensitive regular expressions matched against the name, description and
+// provisions of the packages. Like libalpm, an invalid expression returns
+// an empty list.
+func (db *DB) Search(targets []string) alpm.IPackageList {
+	res := make([]*regexp.Regexp, len(targets))
+	for i, target := range targets {
+		re, err := regexp.Compile("(?i)" + target)
+		if err != nil {
+			return PackageList{}
+		}
+		res[i] = re
+	}
+
+	l := PackageList{}
+	for _, pkg := range db.pkgs {
+		if matchesAll(pkg, res) {
+			l = append(l, pkg)
+		}
+	}
+	return l
+}
+
+func matchesAll(pkg *Package, res []*regexp.Regexp) bool {
+	for _, re := range res {
+		matched := re.MatchString(pkg.name) || re.MatchString(pkg.description)
+		for _, prov := range pkg.provides {
+			matched = matched || re.MatchString(prov.Name)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPGPSignature returns the result set with WithSignature.
+func (db *DB) CheckPGPSignature() (alpm.SigList, error) {
+	return db.sigs, db.sigErr
+}
+
+// DBList is an in-memory alpm.IDBList.
+type DBList struct {
+	dbs []*DB
+}
+
+var _ alpm.IDBList = (*DBList)(nil)
+
+// NewDBList returns a list of dbs, in order of priority.
+func NewDBList(dbs ...*DB) *DBList {
+	l := &DBList{}
+	for _, db := range dbs {
+		db.list = l
+	}
+	l.dbs = append(l.dbs, dbs...)
+	return l
+}
+
+func (l *DBList) ForEach(f func(alpm.IDB) error) error {
+	for _, db := range l.dbs {
+		if err := f(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *DBList) Slice() []alpm.IDB {
+	slice := make([]alpm.IDB, len(l.dbs))
+	for i, db := range l.dbs {
+		slice[i] = db
+	}
+	return slice
+}
+
+// FindGroupPkgs returns the packages of the group across the databases. A
+// package name is only returned once, from the first database holding it,
+// and ignored packages are left out.
+func (l *DBList) FindGroupPkgs(name string) alpm.IPackageList {
+	pkgs := PackageList{}
+	seen := map[string]bool{}
+
+	for _, db := range l.dbs {
+		for _, pkg := range db.pkgs {
+			if seen[pkg.name] || !inGroup(pkg, name) {
+				continue
+			}
+			seen[pkg.name] = true
+			if !pkg.ignore {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+
+	return pkgs
+}
+
+func inGroup(pkg *Package, group string) bool {
+	for _, g := range pkg.groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// FindSatisfier resolves depstring the way libalpm does: a package with the
+// same name in the first database that has one wins over any provider, and
+// databases without install or upgrade usage as well as ignored packages are
+// skipped.
+func (l *DBList) FindSatisfier(depstring string) (alpm.IPackage, error) {
+	dep := parseDepends([]string{depstring})[0]
+
+	var dbs []*DB
+	for _, db := range l.dbs {
+		if db.usage&(alpm.UsageInstall|alpm.UsageUpgrade) != 0 {
+			dbs = append(dbs, db)
+		}
+	}
+
+	for _, db := range dbs {
+		if pkg := db.Pkg(dep.Name); pkg != nil && !pkg.ShouldIgnore() && satisfiesLiteral(pkg, dep) {
+			return pkg, nil
+		}
+	}
+
+	for _, db := range dbs {
+		for _, pkg := range db.pkgs {
+			if pkg.name != dep.Name && !pkg.ignore && satisfiesProvides(pkg, dep) {
+				return pkg, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unable to satisfy dependency %s in DBlist", depstring)
+}
+
+// PackageList is an in-memory alpm.IPackageList.
+type PackageList []alpm.IPackage
+
+var _ alpm.IPackageList = PackageList{}
+
+func (l PackageList) ForEach(f func(alpm.IPackage) error) error {
+	for _, pkg := range l {
+		if err := f(pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l PackageList) Slice() []alpm.IPackage {
+	return append([]alpm.IPackage{}, l...)
+}
+
+// SortBySize returns a copy of the list sorted by installed size, largest
+// first.
+func (l PackageList) SortBySize() alpm.IPackageList {
+	sorted := append(PackageList{}, l...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ISize() > sorted[j].ISize()
+	})
+	return sorted
+}
+
+// FindSatisfier returns the first package of the list named after
+// depstring that satisfies it, or else the first provider.
+func (l PackageList) FindSatisfier(depstring string) (alpm.IPackage, error) {
+	dep := parseDepends([]string{depstring})[0]
+
+	for _, pkg := range l {
+		if satisfiesLiteral(pkg, dep) {
+			return pkg, nil
+		}
+	}
+	for _, pkg := range l {
+		if satisfiesProvides(pkg, dep) {
+			return pkg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unable to find dependency %s in PackageList", depstring)
+}
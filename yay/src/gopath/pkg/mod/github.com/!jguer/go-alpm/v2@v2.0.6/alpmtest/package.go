@@ -0,0 +1,507 @@
+// package.go - In-memory package.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+// Package alpmtest provides in-memory implementations of the go-alpm
+// interfaces, so that code using them can be tested without a libalpm
+// handle or a root filesystem.
+//
+// Packages and databases are built with chained calls:
+//
+//	local := alpmtest.NewLocalDB(
+//		alpmtest.NewPackage("pacman", "6.0.0-1").WithDepends("glibc"),
+//		alpmtest.NewPackage("glibc", "2.33-5"),
+//	)
+//	dbs := alpmtest.NewDBList(alpmtest.NewDB("core", ...))
+package alpmtest
+
+import (
+	"errors"
+	"time"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// Package is an in-memory alpm.IPackage. A package belongs to at most one
+// database.
+type Package struct {
+	name        string
+	version     string
+	base        string
+	description string
+	arch        string
+	url         string
+	packager    string
+	filename    string
+	md5sum      string
+	sha256sum   string
+	signature   string
+	validation  alpm.Validation
+	buildDate   time.Time
+	installDate time.Time
+	size        int64
+	isize       int64
+	reason      alpm.PkgReason
+	ignore      bool
+	typ         string
+
+	depends      []alpm.Depend
+	optDepends   []alpm.Depend
+	makeDepends  []alpm.Depend
+	checkDepends []alpm.Depend
+	conflicts    []alpm.Depend
+	provides     []alpm.Depend
+	replaces     []alpm.Depend
+	groups       []string
+	licenses     []string
+	backup       []alpm.BackupFile
+	files        []alpm.File
+
+	sigs   alpm.SigList
+	sigErr error
+
+	db *DB
+}
+
+var _ alpm.IPackage = (*Package)(nil)
+
+// NewPackage returns a package with the given name and version. The base
+// defaults to the name and the architecture to "any".
+func NewPackage(name, version string) *Package {
+	return &Package{
+		name:    name,
+		version: version,
+		base:    name,
+		arch:    "any",
+	}
+}
+
+// parseDepends parses dependency strings such as "glibc>=2.12: reason".
+func parseDepends(depstrings []string) []alpm.Depend {
+	deps := make([]alpm.Depend, len(depstrings))
+	for i, s := range depstrings {
+		dep := vercmp.ParseDepend(s)
+		deps[i] = alpm.Depend{
+			Name:        dep.Name,
+			Version:     dep.Version,
+			Description: dep.Description,
+			Mod:         alpm.DepMod(dep.Mod),
+		}
+	}
+	return deps
+}
+
+// WithBase sets the package base.
+func (pkg *Package) WithBase(base string) *Package {
+	pkg.base = base
+	return pkg
+}
+
+// WithDescription sets the package description.
+func (pkg *Package) WithDescription(desc string) *Package {
+	pkg.description = desc
+	return pkg
+}
+
+// WithArchitecture sets the package architecture.
+func (pkg *Package) WithArchitecture(arch string) *Package {
+	pkg.arch = arch
+	return pkg
+}
+
+// WithURL sets the upstream URL.
+func (pkg *Package) WithURL(url string) *Package {
+	pkg.url = url
+	return pkg
+}
+
+// WithPackager sets the packager.
+func (pkg *Package) WithPackager(packager string) *Package {
+	pkg.packager = packager
+	return pkg
+}
+
+// WithFileName sets the archive file name. It defaults to
+// name-version-arch.pkg.tar.zst.
+func (pkg *Package) WithFileName(filename string) *Package {
+	pkg.filename = filename
+	return pkg
+}
+
+// WithChecksums sets the MD5 and SHA256 sums of the archive.
+func (pkg *Package) WithChecksums(md5sum, sha256sum string) *Package {
+	pkg.md5sum, pkg.sha256sum = md5sum, sha256sum
+	return pkg
+}
+
+// WithValidation sets how the package was validated on install.
+func (pkg *Package) WithValidation(validation alpm.Validation) *Package {
+	pkg.validation = validation
+	return pkg
+}
+
+// WithSignature sets the base64 encoded signature of the package and the
+// result returned by CheckPGPSignature.
+func (pkg *Package) WithSignature(signature string, sigs alpm.SigList, err error) *Package {
+	pkg.signature, pkg.sigs, pkg.sigErr = signature, sigs, err
+	return pkg
+}
+
+// WithBuildDate sets the build date.
+func (pkg *Package) WithBuildDate(date time.Time) *Package {
+	pkg.buildDate = date
+	return pkg
+}
+
+// WithInstallDate sets the install date.
+func (pkg *Package) WithInstallDate(date time.Time) *Package {
+	pkg.installDate = date
+	return pkg
+}
+
+// WithSize sets the archive and installed sizes.
+func (pkg *Package) WithSize(size, isize int64) *Package {
+	pkg.size, pkg.isize = size, isize
+	return pkg
+}
+
+// WithReason sets the install reason.
+func (pkg *Package) WithReason(reason alpm.PkgReason) *Package {
+	pkg.reason = reason
+	return pkg
+}
+
+// WithIgnore sets whether the package is ignored, as if listed in IgnorePkg.
+func (pkg *Package) WithIgnore(ignore bool) *Package {
+	pkg.ignore = ignore
+	return pkg
+}
+
+// WithType sets the value returned by Type, "alpmtest" by default.
+func (pkg *Package) WithType(typ string) *Package {
+	pkg.typ = typ
+	return pkg
+}
+
+// WithDepends adds dependencies such as "glibc>=2.12".
+func (pkg *Package) WithDepends(deps ...string) *Package {
+	pkg.depends = append(pkg.depends, parseDepends(deps)...)
+	return pkg
+}
+
+// WithOptionalDepends adds optional dependencies such as "git: for VCS
+// packages".
+func (pkg *Package) WithOptionalDepends(deps ...string) *Package {
+	pkg.optDepends = append(pkg.optDepends, parseDepends(deps)...)
+	return pkg
+}
+
+// WithMakeDepends adds make dependencies.
+func (pkg *Package) WithMakeDepends(deps ...string) *Package {
+	pkg.makeDepends = append(pkg.makeDepends, parseDepends(deps)...)
+	return pkg
+}
+
+// WithCheckDepends adds check dependencies.
+func (pkg *Package) WithCheckDepends(deps ...string) *Package {
+	pkg.checkDepends = append(pkg.checkDepends, parseDepends(deps)...)
+	return pkg
+}
+
+// WithConflicts adds conflicts.
+func (pkg *Package) WithConflicts(deps ...string) *Package {
+	pkg.conflicts = append(pkg.conflicts, parseDepends(deps)...)
+	return pkg
+}
+
+// WithProvides adds provisions such as "sh=5.1".
+func (pkg *Package) WithProvides(deps ...string) *Package {
+	pkg.provides = append(pkg.provides, parseDepends(deps)...)
+	return pkg
+}
+
+// WithReplaces adds replaced packages.
+func (pkg *Package) WithReplaces(deps ...string) *Package {
+	pkg.replaces = append(pkg.replaces, parseDepends(deps)...)
+	return pkg
+}
+
+// WithGroups adds the package to groups.
+func (pkg *Package) WithGroups(groups ...string) *Package {
+	pkg.groups = append(pkg.groups, groups...)
+	return pkg
+}
+
+// WithLicenses adds licenses.
+func (pkg *Package) WithLicenses(licenses ...string) *Package {
+	pkg.licenses = append(pkg.licenses, licenses...)
+	return pkg
+}
+
+// WithBackup adds backup files.
+func (pkg *Package) WithBackup(files ...alpm.BackupFile) *Package {
+	pkg.backup = append(pkg.backup, files...)
+	return pkg
+}
+
+// WithFiles adds files to the file list.
+func (pkg *Package) WithFiles(files ...alpm.File) *Package {
+	pkg.files = append(pkg.files, files...)
+	return pkg
+}
+
+func (pkg *Package) FileName() string {
+	if pkg.filename == "" {
+		return pkg.name + "-" + pkg.version + "-" + pkg.arch + ".pkg.tar.zst"
+	}
+	return pkg.filename
+}
+
+func (pkg *Package) Base() string {
+	return pkg.base
+}
+
+func (pkg *Package) Base64Signature() string {
+	return pkg.signature
+}
+
+func (pkg *Package) Validation() alpm.Validation {
+	return pkg.validation
+}
+
+// CheckPGPSignature returns the result set with WithSignature.
+func (pkg *Package) CheckPGPSignature() (alpm.SigList, error) {
+	return pkg.sigs, pkg.sigErr
+}
+
+func (pkg *Package) Architecture() string {
+	return pkg.arch
+}
+
+func (pkg *Package) Backup() alpm.BackupList {
+	return alpm.BackupList(pkg.backup)
+}
+
+func (pkg *Package) BuildDate() time.Time {
+	return pkg.buildDate
+}
+
+func (pkg *Package) Conflicts() alpm.DependList {
+	return alpm.DependList(pkg.conflicts)
+}
+
+// DB returns the database the package was added to, or nil.
+func (pkg *Package) DB() alpm.IDB {
+	if pkg.db == nil {
+		return nil
+	}
+	return pkg.db
+}
+
+func (pkg *Package) Depends() alpm.DependList {
+	return alpm.DependList(pkg.depends)
+}
+
+func (pkg *Package) OptionalDepends() alpm.DependList {
+	return alpm.DependList(pkg.optDepends)
+}
+
+func (pkg *Package) CheckDepends() alpm.DependList {
+	return alpm.DependList(pkg.checkDepends)
+}
+
+func (pkg *Package) MakeDepends() alpm.DependList {
+	return alpm.DependList(pkg.makeDepends)
+}
+
+func (pkg *Package) Description() string {
+	return pkg.description
+}
+
+func (pkg *Package) Files() []alpm.File {
+	return pkg.files
+}
+
+func (pkg *Package) ContainsFile(path string) (alpm.File, error) {
+	for _, file := range pkg.files {
+		if file.Name == path {
+			return file, nil
+		}
+	}
+	return alpm.File{}, errors.New("no file")
+}
+
+func (pkg *Package) Groups() alpm.StringList {
+	return alpm.StringList(pkg.groups)
+}
+
+func (pkg *Package) ISize() int64 {
+	return pkg.isize
+}
+
+func (pkg *Package) InstallDate() time.Time {
+	return pkg.installDate
+}
+
+func (pkg *Package) Licenses() alpm.StringList {
+	return alpm.StringList(pkg.licenses)
+}
+
+func (pkg *Package) SHA256Sum() string {
+	return pkg.sha256sum
+}
+
+func (pkg *Package) MD5Sum() string {
+	return pkg.md5sum
+}
+
+func (pkg *Package) Name() string {
+	return pkg.name
+}
+
+func (pkg *Package) Packager() string {
+	return pkg.packager
+}
+
+func (pkg *Package) Provides() alpm.DependList {
+	return alpm.DependList(pkg.provides)
+}
+
+func (pkg *Package) Reason() alpm.PkgReason {
+	return pkg.reason
+}
+
+// Origin returns FromLocalDB or FromSyncDB depending on the database the
+// package was added to, and FromFile for packages outside of a database.
+func (pkg *Package) Origin() alpm.PkgFrom {
+	switch {
+	case pkg.db == nil:
+		return alpm.FromFile
+	case pkg.db.local:
+		return alpm.FromLocalDB
+	}
+	return alpm.FromSyncDB
+}
+
+func (pkg *Package) Replaces() alpm.DependList {
+	return alpm.DependList(pkg.replaces)
+}
+
+func (pkg *Package) Size() int64 {
+	return pkg.size
+}
+
+func (pkg *Package) URL() string {
+	return pkg.url
+}
+
+func (pkg *Package) Version() string {
+	return pkg.version
+}
+
+// ComputeRequiredBy returns the names of the packages depending on pkg. Like
+// libalpm, local packages are looked up in their database and sync packages
+// in every sync database of the list their database belongs to.
+func (pkg *Package) ComputeRequiredBy() []string {
+	return pkg.computeRequiredBy(func(p *Package) []alpm.Depend { return p.depends })
+}
+
+// ComputeOptionalFor returns the names of the packages optionally depending
+// on pkg, looked up like ComputeRequiredBy.
+func (pkg *Package) ComputeOptionalFor() []string {
+	return pkg.computeRequiredBy(func(p *Package) []alpm.Depend { return p.optDepends })
+}
+
+func (pkg *Package) computeRequiredBy(deps func(*Package) []alpm.Depend) []string {
+	if pkg.db == nil {
+		return nil
+	}
+
+	dbs := []*DB{pkg.db}
+	if !pkg.db.local && pkg.db.list != nil {
+		dbs = nil
+		for _, db := range pkg.db.list.dbs {
+			if !db.local {
+				dbs = append(dbs, db)
+			}
+		}
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, db := range dbs {
+		for _, p := range db.pkgs {
+			for _, dep := range deps(p) {
+				if !seen[p.name] && satisfies(pkg, dep) {
+					seen[p.name] = true
+					names = append(names, p.name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+func (pkg *Package) ShouldIgnore() bool {
+	return pkg.ignore
+}
+
+// SyncNewVersion returns the package of the same name from the first
+// database of l holding it if that package is newer than pkg, or nil.
+func (pkg *Package) SyncNewVersion(l alpm.IDBList) alpm.IPackage {
+	var spkg alpm.IPackage
+	_ = l.ForEach(func(db alpm.IDB) error {
+		spkg = db.Pkg(pkg.name)
+		if spkg != nil {
+			return errStop
+		}
+		return nil
+	})
+
+	if spkg != nil && vercmp.Compare(spkg.Version(), pkg.version) > 0 {
+		return spkg
+	}
+	return nil
+}
+
+func (pkg *Package) Type() string {
+	if pkg.typ != "" {
+		return pkg.typ
+	}
+	return "alpmtest"
+}
+
+// errStop ends ForEach loops early.
+var errStop = errors.New("stop")
+
+func toVercmp(dep alpm.Depend) vercmp.Depend {
+	return vercmp.Depend{Name: dep.Name, Version: dep.Version, Mod: vercmp.DepMod(dep.Mod)}
+}
+
+// satisfiesLiteral reports whether the name and version of pkg satisfy dep.
+func satisfiesLiteral(pkg alpm.IPackage, dep alpm.Depend) bool {
+	return toVercmp(dep).Satisfies(pkg.Name(), pkg.Version())
+}
+
+// satisfiesProvides reports whether one of the provisions of pkg satisfies
+// dep. Unversioned provisions only satisfy unversioned dependencies.
+func satisfiesProvides(pkg alpm.IPackage, dep alpm.Depend) bool {
+	for _, prov := range pkg.Provides().Slice() {
+		version := prov.Version
+		if prov.Mod == alpm.DepModAny {
+			version = ""
+		}
+		if toVercmp(dep).Satisfies(prov.Name, version) {
+			return true
+		}
+	}
+	return false
+}
+
+func satisfies(pkg alpm.IPackage, dep alpm.Depend) bool {
+	return satisfiesLiteral(pkg, dep) || satisfiesProvides(pkg, dep)
+}
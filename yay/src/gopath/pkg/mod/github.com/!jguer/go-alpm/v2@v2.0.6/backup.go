@@ -0,0 +1,26 @@
+//go:build cgo
+// +build cgo
+
+// backup.go - Audit the backup files of installed packages.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+// AuditBackups compares the backup files of the installed packages with
+// their hash in the local database, like pacman -Qii, and looks for
+// .pacnew and .pacsave files left next to them.
+func (h *Handle) AuditBackups() ([]BackupAudit, error) {
+	root, err := h.Root()
+	if err != nil {
+		return nil, err
+	}
+	db, err := h.LocalDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return AuditBackupFiles(db, root)
+}
@@ -0,0 +1,81 @@
+// backup_test.go - Tests for the backup file audit, using in-memory packages.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm_test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/alpmtest"
+)
+
+func TestAuditBackupFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "etc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name, content string) string {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sum := md5.Sum([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	unmodified := write("etc/pacman.conf", "[options]\n")
+	original := write("etc/makepkg.conf", "MAKEFLAGS=\n")
+	write("etc/makepkg.conf", "MAKEFLAGS=-j8\n")
+	write("etc/makepkg.conf.pacnew", "MAKEFLAGS=\nLTO=1\n")
+	write("etc/makepkg.conf.pacsave", "old")
+	write("etc/makepkg.conf.pacsave.1624000000", "older")
+
+	db := alpmtest.NewLocalDB(
+		alpmtest.NewPackage("pacman", "6.0.0-1").WithBackup(
+			alpm.BackupFile{Name: "etc/pacman.conf", Hash: unmodified},
+			alpm.BackupFile{Name: "etc/makepkg.conf", Hash: original},
+		),
+		alpmtest.NewPackage("sudo", "1.9-1").WithBackup(alpm.BackupFile{Name: "etc/sudoers", Hash: unmodified}),
+	)
+
+	audits, err := alpm.AuditBackupFiles(db, root)
+	if err != nil {
+		t.Fatalf("AuditBackupFiles failed: %s", err)
+	}
+	if len(audits) != 3 {
+		t.Fatalf("got %d audits, want 3", len(audits))
+	}
+
+	tests := []struct {
+		status  alpm.BackupStatus
+		pacnew  string
+		pacsave []string
+	}{
+		{alpm.BackupUnmodified, "", nil},
+		{alpm.BackupModified, filepath.Join(root, "etc/makepkg.conf.pacnew"), []string{
+			filepath.Join(root, "etc/makepkg.conf.pacsave"),
+			filepath.Join(root, "etc/makepkg.conf.pacsave.1624000000"),
+		}},
+		{alpm.BackupMissing, "", nil},
+	}
+
+	for i, tt := range tests {
+		a := audits[i]
+		if a.Status != tt.status || a.Pacnew != tt.pacnew || !reflect.DeepEqual(a.Pacsave, tt.pacsave) {
+			t.Errorf("%s: got %s %q %v, want %s %q %v", a.File, a.Status, a.Pacnew, a.Pacsave, tt.status, tt.pacnew, tt.pacsave)
+		}
+	}
+	if audits[2].Package.Name() != "sudo" || audits[2].File != filepath.Join(root, "etc/sudoers") {
+		t.Errorf("unexpected audit %+v", audits[2])
+	}
+}
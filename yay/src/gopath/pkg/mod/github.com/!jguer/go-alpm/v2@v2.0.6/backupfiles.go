@@ -0,0 +1,95 @@
+// backupfiles.go - Audit backup files against a local database.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BackupAudit is the state of a backup file of an installed package.
+type BackupAudit struct {
+	Package IPackage
+	// File is the path of the file, including the root.
+	File   string
+	Status BackupStatus
+	// Pacnew holds the .pacnew file left by an upgrade, if any.
+	Pacnew string
+	// Pacsave holds the .pacsave files next to the file, sorted.
+	Pacsave []string
+}
+
+// AuditBackupFiles audits the backup files of the packages of db, a local
+// database installed in root.
+func AuditBackupFiles(db IDB, root string) ([]BackupAudit, error) {
+	audits := []BackupAudit{}
+
+	for _, pkg := range db.PkgCache().Slice() {
+		for _, backup := range pkg.Backup().Slice() {
+			audit := BackupAudit{
+				Package: pkg,
+				File:    filepath.Join(root, backup.Name),
+				Status:  backupStatus(filepath.Join(root, backup.Name), backup.Hash),
+			}
+
+			if _, err := os.Lstat(audit.File + ".pacnew"); err == nil {
+				audit.Pacnew = audit.File + ".pacnew"
+			}
+
+			// a .pacsave that already exists is kept and the new one gets a
+			// timestamp suffix
+			pacsaves, err := filepath.Glob(globEscape(audit.File) + ".pacsave*")
+			if err != nil {
+				return nil, err
+			}
+			sort.Strings(pacsaves)
+			audit.Pacsave = pacsaves
+
+			audits = append(audits, audit)
+		}
+	}
+
+	return audits, nil
+}
+
+func backupStatus(path, hash string) BackupStatus {
+	f, err := os.Open(path)
+	switch {
+	case os.IsNotExist(err):
+		return BackupMissing
+	case err != nil:
+		return BackupUnreadable
+	}
+	defer f.Close()
+
+	sum := md5.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return BackupUnreadable
+	}
+	if hex.EncodeToString(sum.Sum(nil)) != hash {
+		return BackupModified
+	}
+
+	return BackupUnmodified
+}
+
+// globEscape escapes the glob metacharacters of path.
+func globEscape(path string) string {
+	escaped := make([]rune, 0, len(path))
+	for _, r := range path {
+		switch r {
+		case '*', '?', '[', '\\':
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, r)
+	}
+	return string(escaped)
+}
@@ -0,0 +1,279 @@
+// cache.go - Package cache inspection and cleaning.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+// Package cache inspects the package cache directories and removes stale
+// package files, like pacman -Sc and paccache.
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// Status is the installation status of a cached package.
+type Status int
+
+const (
+	NotInstalled   Status = iota // No version of the package is installed.
+	Installed                    // This version is installed.
+	OtherInstalled               // Another version is installed.
+)
+
+func (s Status) String() string {
+	switch s {
+	case NotInstalled:
+		return "not installed"
+	case Installed:
+		return "installed"
+	case OtherInstalled:
+		return "other version installed"
+	}
+	return ""
+}
+
+// Entry is a package file of the cache.
+type Entry struct {
+	Path    string
+	Name    string
+	Version string
+	Arch    string
+	// Size is the size of the package file and its signature, if any.
+	Size int64
+	// SigPath is the path of the detached signature, empty if there is none.
+	SigPath string
+
+	Status Status
+	// SyncDB is the name of the first sync database holding this version,
+	// empty if none does.
+	SyncDB string
+}
+
+// ParseFileName splits a package file name of the form
+// name-pkgver-pkgrel-arch.pkg.tar[.ext] into the package name, its full
+// version and architecture.
+func ParseFileName(file string) (name, version, arch string, err error) {
+	i := strings.LastIndex(file, ".pkg.tar")
+	if i < 0 {
+		return "", "", "", fmt.Errorf("%s is not a package file", file)
+	}
+
+	parts := strings.Split(file[:i], "-")
+	if len(parts) < 4 {
+		return "", "", "", fmt.Errorf("invalid package file name: %s", file)
+	}
+
+	n := len(parts)
+	name = strings.Join(parts[:n-3], "-")
+	version = parts[n-3] + "-" + parts[n-2]
+	arch = parts[n-1]
+	if name == "" || parts[n-3] == "" || parts[n-2] == "" || arch == "" {
+		return "", "", "", fmt.Errorf("invalid package file name: %s", file)
+	}
+
+	return name, version, arch, nil
+}
+
+// Scan lists the package files of the cache directories. Signatures are
+// attached to their package, other files, such as partial downloads, are
+// skipped. Missing directories are skipped too.
+func Scan(dirs ...string) ([]*Entry, error) {
+	entries := []*Entry{}
+
+	for _, dir := range dirs {
+		infos, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sigs := map[string]os.FileInfo{}
+		for _, info := range infos {
+			if strings.HasSuffix(info.Name(), ".sig") {
+				sigs[strings.TrimSuffix(info.Name(), ".sig")] = info
+			}
+		}
+
+		for _, info := range infos {
+			if !info.Mode().IsRegular() || strings.HasSuffix(info.Name(), ".sig") || strings.HasSuffix(info.Name(), ".part") {
+				continue
+			}
+
+			name, version, arch, err := ParseFileName(info.Name())
+			if err != nil {
+				continue
+			}
+
+			entry := &Entry{
+				Path:    filepath.Join(dir, info.Name()),
+				Name:    name,
+				Version: version,
+				Arch:    arch,
+				Size:    info.Size(),
+			}
+			if sig, ok := sigs[info.Name()]; ok {
+				entry.SigPath = entry.Path + ".sig"
+				entry.Size += sig.Size()
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// Annotate sets the status of the entries from the local database and the
+// sync databases. Either may be nil.
+func Annotate(entries []*Entry, local alpm.IDB, syncDBs alpm.IDBList) {
+	var dbs []alpm.IDB
+	if syncDBs != nil {
+		dbs = syncDBs.Slice()
+	}
+
+	for _, entry := range entries {
+		entry.Status = NotInstalled
+		if local != nil {
+			if pkg := local.Pkg(entry.Name); pkg != nil {
+				entry.Status = OtherInstalled
+				if vercmp.Compare(pkg.Version(), entry.Version) == 0 {
+					entry.Status = Installed
+				}
+			}
+		}
+
+		entry.SyncDB = ""
+		for _, db := range dbs {
+			if pkg := db.Pkg(entry.Name); pkg != nil && vercmp.Compare(pkg.Version(), entry.Version) == 0 {
+				entry.SyncDB = db.Name()
+				break
+			}
+		}
+	}
+}
+
+// Group holds the cached versions of a package for one architecture,
+// newest first. Copies of the same version in several cache directories
+// are kept as separate entries.
+type Group struct {
+	Name    string
+	Arch    string
+	Entries []*Entry
+}
+
+// GroupEntries groups entries by package name and architecture. The groups
+// are sorted by name, then architecture.
+func GroupEntries(entries []*Entry) []*Group {
+	byKey := map[string]*Group{}
+	groups := []*Group{}
+
+	for _, entry := range entries {
+		key := entry.Name + "\x00" + entry.Arch
+		g, ok := byKey[key]
+		if !ok {
+			g = &Group{Name: entry.Name, Arch: entry.Arch}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Entries = append(g.Entries, entry)
+	}
+
+	for _, g := range groups {
+		sort.SliceStable(g.Entries, func(i, j int) bool {
+			return vercmp.Compare(g.Entries[i].Version, g.Entries[j].Version) > 0
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Name != groups[j].Name {
+			return groups[i].Name < groups[j].Name
+		}
+		return groups[i].Arch < groups[j].Arch
+	})
+
+	return groups
+}
+
+// Policy selects the cached packages to remove. The zero Policy removes
+// everything, like pacman -Scc.
+type Policy struct {
+	// Keep is the number of most recent versions kept of each package, like
+	// paccache -k.
+	Keep int
+	// KeepInstalled keeps the installed version of each package, on top of
+	// the Keep most recent ones, like pacman -Sc.
+	KeepInstalled bool
+	// UninstalledOnly only removes packages of which no version is
+	// installed, like paccache -u.
+	UninstalledOnly bool
+}
+
+// Plan is the outcome of a Policy. It is a dry run until Apply is called.
+type Plan struct {
+	Remove []*Entry
+	Keep   []*Entry
+	// Reclaimed is the number of bytes freed by removing the files.
+	Reclaimed int64
+}
+
+// NewPlan applies policy to annotated entries. Versions are counted per
+// package name and architecture, and copies of a version in several
+// directories count as one version.
+func NewPlan(entries []*Entry, policy Policy) *Plan {
+	plan := &Plan{}
+
+	for _, g := range GroupEntries(entries) {
+		uninstalled := true
+		for _, entry := range g.Entries {
+			if entry.Status != NotInstalled {
+				uninstalled = false
+			}
+		}
+
+		versions := 0
+		for i, entry := range g.Entries {
+			if i == 0 || vercmp.Compare(entry.Version, g.Entries[i-1].Version) != 0 {
+				versions++
+			}
+
+			keep := versions <= policy.Keep ||
+				(policy.KeepInstalled && entry.Status == Installed) ||
+				(policy.UninstalledOnly && !uninstalled)
+			if keep {
+				plan.Keep = append(plan.Keep, entry)
+				continue
+			}
+
+			plan.Remove = append(plan.Remove, entry)
+			plan.Reclaimed += entry.Size
+		}
+	}
+
+	return plan
+}
+
+// Apply removes the package files of the plan and their signatures. It
+// stops at the first error.
+func (p *Plan) Apply() error {
+	for _, entry := range p.Remove {
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if entry.SigPath == "" {
+			continue
+		}
+		if err := os.Remove(entry.SigPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// cache_test.go - Tests for the package cache.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Jguer/go-alpm/v2/alpmtest"
+)
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		file                string
+		name, version, arch string
+	}{
+		{"pacman-6.0.0-1-x86_64.pkg.tar.zst", "pacman", "6.0.0-1", "x86_64"},
+		{"python-foo-bar-1.0-2-any.pkg.tar.xz", "python-foo-bar", "1.0-2", "any"},
+		{"zlib-1:1.2.11-4-x86_64.pkg.tar", "zlib", "1:1.2.11-4", "x86_64"},
+		{"pacman-6.0.0-x86_64.pkg.tar.zst", "", "", ""},
+		{"pacman.db", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		name, version, arch, err := ParseFileName(tt.file)
+		if tt.name == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.file)
+			}
+			continue
+		}
+		if err != nil || name != tt.name || version != tt.version || arch != tt.arch {
+			t.Errorf("%s: got %s %s %s %v", tt.file, name, version, arch, err)
+		}
+	}
+}
+
+func writeCache(t *testing.T, dir string, files map[string]int) {
+	t.Helper()
+	for name, size := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testEntries(t *testing.T) (string, []*Entry) {
+	dir := t.TempDir()
+	writeCache(t, dir, map[string]int{
+		"pacman-5.2.2-4-x86_64.pkg.tar.zst":      100,
+		"pacman-6.0.0-1-x86_64.pkg.tar.zst":      110,
+		"pacman-6.0.0-1-x86_64.pkg.tar.zst.sig":  1,
+		"pacman-6.0.1-1-x86_64.pkg.tar.zst":      120,
+		"pacman-6.0.1-2-x86_64.pkg.tar.zst.part": 50,
+		"vim-8.2-1-x86_64.pkg.tar.zst":           30,
+		"vim-8.10-1-x86_64.pkg.tar.zst":          40,
+		"unrelated.txt":                          5,
+	})
+
+	entries, err := Scan(dir, filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("Scan failed: %s", err)
+	}
+
+	local := alpmtest.NewLocalDB(alpmtest.NewPackage("pacman", "6.0.0-1"))
+	core := alpmtest.NewDB("core", alpmtest.NewPackage("pacman", "6.0.1-1"))
+	Annotate(entries, local, alpmtest.NewDBList(core))
+
+	return dir, entries
+}
+
+func versions(entries []*Entry) []string {
+	vs := []string{}
+	for _, entry := range entries {
+		vs = append(vs, entry.Name+"-"+entry.Version)
+	}
+	return vs
+}
+
+func TestScanAndGroup(t *testing.T) {
+	_, entries := testEntries(t)
+
+	groups := GroupEntries(entries)
+	if len(groups) != 2 || groups[0].Name != "pacman" || groups[1].Name != "vim" {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+
+	pacman := groups[0].Entries
+	want := []string{"pacman-6.0.1-1", "pacman-6.0.0-1", "pacman-5.2.2-4"}
+	if got := versions(pacman); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if pacman[0].Status != OtherInstalled || pacman[0].SyncDB != "core" {
+		t.Errorf("pacman 6.0.1 is %s in %q", pacman[0].Status, pacman[0].SyncDB)
+	}
+	if pacman[1].Status != Installed || pacman[1].Size != 111 || pacman[1].SigPath == "" {
+		t.Errorf("unexpected entry %+v", pacman[1])
+	}
+	if got := versions(groups[1].Entries); !reflect.DeepEqual(got, []string{"vim-8.10-1", "vim-8.2-1"}) {
+		t.Errorf("vim versions are not sorted with vercmp: %v", got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	_, entries := testEntries(t)
+
+	tests := []struct {
+		name      string
+		policy    Policy
+		remove    []string
+		reclaimed int64
+	}{
+		{"remove all", Policy{}, []string{"pacman-6.0.1-1", "pacman-6.0.0-1", "pacman-5.2.2-4", "vim-8.10-1", "vim-8.2-1"}, 401},
+		{"keep one", Policy{Keep: 1}, []string{"pacman-6.0.0-1", "pacman-5.2.2-4", "vim-8.2-1"}, 241},
+		{"keep installed", Policy{KeepInstalled: true}, []string{"pacman-6.0.1-1", "pacman-5.2.2-4", "vim-8.10-1", "vim-8.2-1"}, 290},
+		{"uninstalled", Policy{UninstalledOnly: true}, []string{"vim-8.10-1", "vim-8.2-1"}, 70},
+		{"uninstalled keep one", Policy{Keep: 1, UninstalledOnly: true}, []string{"vim-8.2-1"}, 30},
+	}
+
+	for _, tt := range tests {
+		plan := NewPlan(entries, tt.policy)
+		if got := versions(plan.Remove); !reflect.DeepEqual(got, tt.remove) {
+			t.Errorf("%s: removes %v, want %v", tt.name, got, tt.remove)
+		}
+		if len(plan.Remove)+len(plan.Keep) != len(entries) {
+			t.Errorf("%s: entries lost", tt.name)
+		}
+		if plan.Reclaimed != tt.reclaimed {
+			t.Errorf("%s: reclaims %d, want %d", tt.name, plan.Reclaimed, tt.reclaimed)
+		}
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	dir, entries := testEntries(t)
+
+	if err := NewPlan(entries, Policy{Keep: 1}).Apply(); err != nil {
+		t.Fatalf("Apply failed: %s", err)
+	}
+
+	for _, name := range []string{"pacman-6.0.0-1-x86_64.pkg.tar.zst", "pacman-6.0.0-1-x86_64.pkg.tar.zst.sig", "vim-8.2-1-x86_64.pkg.tar.zst"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", name)
+		}
+	}
+
+	left, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(left); !reflect.DeepEqual(got, []string{"pacman-6.0.1-1", "vim-8.10-1"}) {
+		t.Errorf("cache holds %v", got)
+	}
+}
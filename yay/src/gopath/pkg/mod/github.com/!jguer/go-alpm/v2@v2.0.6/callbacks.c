@@ -9,13 +9,10 @@
 #include <stdarg.h>
 #include "callbacks.h"
 
-typedef struct {
-  void *go_cb;
-  go_ctx_t go_ctx;
-} go_alpm_context_t;
+// The callback context given to libalpm is a token from the Go callback
+// registry, Go looks up the registered callbacks from it.
 
-static void _log_cb(void *c_ctx, alpm_loglevel_t level, const char *fmt, va_list arg) {
-  go_alpm_context_t *ctx = c_ctx;
+static void _log_cb(void *go_ctx, alpm_loglevel_t level, const char *fmt, va_list arg) {
   char *s = malloc(128);
   if (s == NULL) return;
   int16_t length = vsnprintf(s, 128, fmt, arg);
@@ -24,35 +21,52 @@ static void _log_cb(void *c_ctx, alpm_loglevel_t level, const char *fmt, va_list
     s = realloc(s, length);
   }
   if (s != NULL) {
-    go_alpm_go_log_callback(&ctx->go_cb, ctx->go_ctx, level, s);
+    go_alpm_go_log_callback(go_ctx, level, s);
 		free(s);
   }
 }
 
-static void _question_cb(void *c_ctx, alpm_question_t *question) {
-  go_alpm_context_t *ctx = c_ctx;
-  go_alpm_go_question_callback(&ctx->go_cb, ctx->go_ctx, question);
+static void _question_cb(void *go_ctx, alpm_question_t *question) {
+  go_alpm_go_question_callback(go_ctx, question);
 }
 
-static void *alloc_ctx(go_alpm_context_t *ctx, void *go_cb, go_ctx_t go_ctx) {
-  if (ctx == NULL ) {
-    ctx = malloc(sizeof(go_alpm_context_t));
-  }
+static void _event_cb(void *go_ctx, alpm_event_t *event) {
+  go_alpm_go_event_callback(go_ctx, event);
+}
+
+static void _progress_cb(void *go_ctx, alpm_progress_t progress, const char *pkg,
+    int percent, size_t howmany, size_t current) {
+  go_alpm_go_progress_callback(go_ctx, progress, (char *)pkg, percent, howmany, current);
+}
 
-  ctx->go_cb = go_cb;
-  ctx->go_ctx = go_ctx;
+static void _download_cb(void *go_ctx, const char *filename, alpm_download_event_type_t event, void *data) {
+  go_alpm_go_download_callback(go_ctx, (char *)filename, event, data);
+}
+
+static int _fetch_cb(void *go_ctx, const char *url, const char *localpath, int force) {
+  return go_alpm_go_fetch_callback(go_ctx, (char *)url, (char *)localpath, force);
+}
+
+void go_alpm_set_log_callback(alpm_handle_t *handle, uintptr_t go_ctx) {
+  alpm_option_set_logcb(handle, _log_cb, (void *)go_ctx);
+}
+
+void go_alpm_set_question_callback(alpm_handle_t *handle, uintptr_t go_ctx) {
+  alpm_option_set_questioncb(handle, _question_cb, (void *)go_ctx);
+}
+
+void go_alpm_set_event_callback(alpm_handle_t *handle, uintptr_t go_ctx) {
+  alpm_option_set_eventcb(handle, _event_cb, (void *)go_ctx);
+}
 
-  return ctx;
+void go_alpm_set_progress_callback(alpm_handle_t *handle, uintptr_t go_ctx) {
+  alpm_option_set_progresscb(handle, _progress_cb, (void *)go_ctx);
 }
 
-void go_alpm_set_log_callback(alpm_handle_t *handle, void *go_cb, go_ctx_t go_ctx) {
-  void *ctx = alpm_option_get_logcb_ctx(handle);
-  ctx = alloc_ctx(ctx, *(void**)go_cb, go_ctx);
-  alpm_option_set_logcb(handle, _log_cb, ctx);
+void go_alpm_set_download_callback(alpm_handle_t *handle, uintptr_t go_ctx) {
+  alpm_option_set_dlcb(handle, _download_cb, (void *)go_ctx);
 }
 
-void go_alpm_set_question_callback(alpm_handle_t *handle, void *go_cb, go_ctx_t go_ctx) {
-  void *ctx = alpm_option_get_questioncb_ctx(handle);
-  ctx = alloc_ctx(ctx, *(void**)go_cb, go_ctx);
-  alpm_option_set_questioncb(handle, _question_cb, ctx);
+void go_alpm_set_fetch_callback(alpm_handle_t *handle, uintptr_t go_ctx) {
+  alpm_option_set_fetchcb(handle, _fetch_cb, (void *)go_ctx);
 }
@@ -10,20 +10,112 @@ package alpm
 #include "callbacks.h"
 */
 import "C"
-import "unsafe"
+
+import (
+	"sync"
+	"unsafe"
+)
 
 var DefaultLogLevel = LogWarning
 
 type (
 	logCallbackSig      func(interface{}, LogLevel, string)
 	questionCallbackSig func(interface{}, QuestionAny)
-	callbackContextPool map[C.go_ctx_t]interface{}
+	eventCallbackSig    func(interface{}, Event)
+	progressCallbackSig func(interface{}, Progress, string, int, uint, uint)
+	downloadCallbackSig func(interface{}, string, DownloadEvent)
+	fetchCallbackSig    func(interface{}, string, string, bool) int
 )
 
-var (
-	logCallbackContextPool      callbackContextPool = callbackContextPool{}
-	questionCallbackContextPool callbackContextPool = callbackContextPool{}
-)
+// handleCallbacks holds the Go callbacks and contexts registered on a handle.
+// libalpm is only given a token as callback context, so no Go pointer is ever
+// stored in C memory.
+type handleCallbacks struct {
+	handle      *C.alpm_handle_t
+	log         logCallbackSig
+	logCtx      interface{}
+	question    questionCallbackSig
+	questionCtx interface{}
+	event       eventCallbackSig
+	eventCtx    interface{}
+	progress    progressCallbackSig
+	progressCtx interface{}
+	download    downloadCallbackSig
+	downloadCtx interface{}
+	fetch       fetchCallbackSig
+	fetchCtx    interface{}
+}
+
+// callbackRegistry maps the tokens given to libalpm to the callbacks of a
+// handle. Tokens are never reused, unlike handle pointers which libalpm may
+// hand out again once a handle is released.
+var callbackRegistry = struct {
+	sync.RWMutex
+	last    uintptr
+	tokens  map[*C.alpm_handle_t]uintptr
+	handles map[uintptr]*handleCallbacks
+}{
+	tokens:  map[*C.alpm_handle_t]uintptr{},
+	handles: map[uintptr]*handleCallbacks{},
+}
+
+// setCallbacks updates the callbacks registered on ptr under the registry lock
+// and returns the token of the handle.
+func setCallbacks(ptr *C.alpm_handle_t, f func(*handleCallbacks)) C.uintptr_t {
+	callbackRegistry.Lock()
+	defer callbackRegistry.Unlock()
+
+	token, ok := callbackRegistry.tokens[ptr]
+	if !ok {
+		callbackRegistry.last++
+		token = callbackRegistry.last
+		callbackRegistry.tokens[ptr] = token
+		callbackRegistry.handles[token] = &handleCallbacks{handle: ptr}
+	}
+	f(callbackRegistry.handles[token])
+	return C.uintptr_t(token)
+}
+
+// getCallbacks returns a copy of the callbacks registered under the token
+// goCtx, so that callbacks run without holding the registry lock.
+func getCallbacks(goCtx C.go_ctx_t) handleCallbacks {
+	callbackRegistry.RLock()
+	defer callbackRegistry.RUnlock()
+
+	if cbs, ok := callbackRegistry.handles[uintptr(goCtx)]; ok {
+		return *cbs
+	}
+	return handleCallbacks{}
+}
+
+// detachCallbacks unlinks the callbacks of ptr from the handle pointer and
+// returns their token. The callbacks stay registered under the token, so they
+// still run while libalpm releases the handle, but a handle allocated at the
+// same address afterwards gets its own token.
+func detachCallbacks(ptr *C.alpm_handle_t) (uintptr, bool) {
+	callbackRegistry.Lock()
+	defer callbackRegistry.Unlock()
+
+	token, ok := callbackRegistry.tokens[ptr]
+	delete(callbackRegistry.tokens, ptr)
+	return token, ok
+}
+
+// attachCallbacks links the callbacks of token back to ptr.
+func attachCallbacks(ptr *C.alpm_handle_t, token uintptr) {
+	callbackRegistry.Lock()
+	defer callbackRegistry.Unlock()
+
+	callbackRegistry.tokens[ptr] = token
+}
+
+// unregisterCallbacks drops every callback registered under token.
+func unregisterCallbacks(token uintptr) {
+	callbackRegistry.Lock()
+	defer callbackRegistry.Unlock()
+
+	delete(callbackRegistry.handles, token)
+}
 
 func DefaultLogCallback(ctx interface{}, lvl LogLevel, s string) {
 	if lvl <= DefaultLogLevel {
@@ -32,37 +124,122 @@ func DefaultLogCallback(ctx interface{}, lvl LogLevel, s string) {
 }
 
 //export go_alpm_go_log_callback
-func go_alpm_go_log_callback(goCb unsafe.Pointer, goCtx C.go_ctx_t, lvl C.alpm_loglevel_t, s *C.char) {
-	cb := *(*logCallbackSig)(goCb)
-	ctx := logCallbackContextPool[goCtx]
+func go_alpm_go_log_callback(goCtx C.go_ctx_t, lvl C.alpm_loglevel_t, s *C.char) {
+	cbs := getCallbacks(goCtx)
+	if cbs.log == nil {
+		return
+	}
 
-	cb(ctx, LogLevel(lvl), C.GoString(s))
+	cbs.log(cbs.logCtx, LogLevel(lvl), C.GoString(s))
 }
 
 //export go_alpm_go_question_callback
-func go_alpm_go_question_callback(goCb unsafe.Pointer, goCtx C.go_ctx_t, question *C.alpm_question_t) {
+func go_alpm_go_question_callback(goCtx C.go_ctx_t, question *C.alpm_question_t) {
+	cbs := getCallbacks(goCtx)
+	if cbs.question == nil {
+		return
+	}
+
 	q := (*C.alpm_question_any_t)(unsafe.Pointer(question))
+	cbs.question(cbs.questionCtx, QuestionAny{q})
+}
 
-	cb := *(*questionCallbackSig)(goCb)
-	ctx := questionCallbackContextPool[goCtx]
+//export go_alpm_go_event_callback
+func go_alpm_go_event_callback(goCtx C.go_ctx_t, event *C.alpm_event_t) {
+	cbs := getCallbacks(goCtx)
+	if cbs.event == nil {
+		return
+	}
 
-	cb(ctx, QuestionAny{q})
+	cbs.event(cbs.eventCtx, convertEvent(event, Handle{cbs.handle}))
 }
 
-func (h *Handle) SetLogCallback(cb logCallbackSig, ctx interface{}) {
-	goCb := unsafe.Pointer(&cb)
-	goCtx := C.go_ctx_t(h.ptr)
+//export go_alpm_go_progress_callback
+func go_alpm_go_progress_callback(goCtx C.go_ctx_t, progress C.alpm_progress_t,
+	pkg *C.char, percent C.int, howmany, current C.size_t) {
+	cbs := getCallbacks(goCtx)
+	if cbs.progress == nil {
+		return
+	}
 
-	logCallbackContextPool[goCtx] = ctx
+	cbs.progress(cbs.progressCtx, Progress(progress), C.GoString(pkg), int(percent), uint(howmany), uint(current))
+}
 
-	C.go_alpm_set_log_callback(h.ptr, goCb, goCtx)
+//export go_alpm_go_download_callback
+func go_alpm_go_download_callback(goCtx C.go_ctx_t, filename *C.char,
+	event C.alpm_download_event_type_t, data unsafe.Pointer) {
+	cbs := getCallbacks(goCtx)
+	if cbs.download == nil {
+		return
+	}
+
+	cbs.download(cbs.downloadCtx, C.GoString(filename), convertDownloadEvent(event, data))
+}
+
+//export go_alpm_go_fetch_callback
+func go_alpm_go_fetch_callback(goCtx C.go_ctx_t, url, localpath *C.char, force C.int) C.int {
+	cbs := getCallbacks(goCtx)
+	if cbs.fetch == nil {
+		return -1
+	}
+
+	return C.int(cbs.fetch(cbs.fetchCtx, C.GoString(url), C.GoString(localpath), force != 0))
+}
+
+func (h *Handle) SetLogCallback(cb logCallbackSig, ctx interface{}) {
+	token := setCallbacks(h.ptr, func(cbs *handleCallbacks) {
+		cbs.log, cbs.logCtx = cb, ctx
+	})
+
+	C.go_alpm_set_log_callback(h.ptr, token)
 }
 
 func (h *Handle) SetQuestionCallback(cb questionCallbackSig, ctx interface{}) {
-	goCb := unsafe.Pointer(&cb)
-	goCtx := C.go_ctx_t(h.ptr)
+	token := setCallbacks(h.ptr, func(cbs *handleCallbacks) {
+		cbs.question, cbs.questionCtx = cb, ctx
+	})
+
+	C.go_alpm_set_question_callback(h.ptr, token)
+}
+
+// SetEventCallback sets the callback called for transaction, download and
+// hook events. The callback receives one of the Event* types.
+func (h *Handle) SetEventCallback(cb eventCallbackSig, ctx interface{}) {
+	token := setCallbacks(h.ptr, func(cbs *handleCallbacks) {
+		cbs.event, cbs.eventCtx = cb, ctx
+	})
+
+	C.go_alpm_set_event_callback(h.ptr, token)
+}
+
+// SetProgressCallback sets the callback called with the progress of the
+// current operation on a package, as a percent and the position of the
+// package among howmany targets.
+func (h *Handle) SetProgressCallback(cb progressCallbackSig, ctx interface{}) {
+	token := setCallbacks(h.ptr, func(cbs *handleCallbacks) {
+		cbs.progress, cbs.progressCtx = cb, ctx
+	})
+
+	C.go_alpm_set_progress_callback(h.ptr, token)
+}
+
+// SetDownloadCallback sets the callback called for every download event of
+// the internal downloader.
+func (h *Handle) SetDownloadCallback(cb downloadCallbackSig, ctx interface{}) {
+	token := setCallbacks(h.ptr, func(cbs *handleCallbacks) {
+		cbs.download, cbs.downloadCtx = cb, ctx
+	})
+
+	C.go_alpm_set_download_callback(h.ptr, token)
+}
 
-	questionCallbackContextPool[goCtx] = ctx
+// SetFetchCallback replaces the internal downloader. The callback downloads
+// url into the localpath directory and returns 0 on success, 1 if the file
+// is already up to date and -1 on error.
+func (h *Handle) SetFetchCallback(cb fetchCallbackSig, ctx interface{}) {
+	token := setCallbacks(h.ptr, func(cbs *handleCallbacks) {
+		cbs.fetch, cbs.fetchCtx = cb, ctx
+	})
 
-	C.go_alpm_set_question_callback(h.ptr, goCb, goCtx)
+	C.go_alpm_set_fetch_callback(h.ptr, token)
 }
@@ -1,9 +1,18 @@
+#include <stdint.h>
 #include <alpm.h>
 
 typedef void *go_ctx_t;
 
-void go_alpm_go_log_callback(void *go_cb, go_ctx_t go_ctx, alpm_loglevel_t level, char *message);
-void go_alpm_go_question_callback(void *go_cb, go_ctx_t go_ctx, alpm_question_t *question);
+void go_alpm_go_log_callback(go_ctx_t go_ctx, alpm_loglevel_t level, char *message);
+void go_alpm_go_question_callback(go_ctx_t go_ctx, alpm_question_t *question);
+void go_alpm_go_event_callback(go_ctx_t go_ctx, alpm_event_t *event);
+void go_alpm_go_progress_callback(go_ctx_t go_ctx, alpm_progress_t progress, char *pkg, int percent, size_t howmany, size_t current);
+void go_alpm_go_download_callback(go_ctx_t go_ctx, char *filename, alpm_download_event_type_t event, void *data);
+int go_alpm_go_fetch_callback(go_ctx_t go_ctx, char *url, char *localpath, int force);
 
-void go_alpm_set_log_callback(alpm_handle_t *handle, void *go_cb, go_ctx_t go_ctx);
-void go_alpm_set_question_callback(alpm_handle_t *handle, void *go_cb, go_ctx_t go_ctx);
+void go_alpm_set_log_callback(alpm_handle_t *handle, uintptr_t go_ctx);
+void go_alpm_set_question_callback(alpm_handle_t *handle, uintptr_t go_ctx);
+void go_alpm_set_event_callback(alpm_handle_t *handle, uintptr_t go_ctx);
+void go_alpm_set_progress_callback(alpm_handle_t *handle, uintptr_t go_ctx);
+void go_alpm_set_download_callback(alpm_handle_t *handle, uintptr_t go_ctx);
+void go_alpm_set_fetch_callback(alpm_handle_t *handle, uintptr_t go_ctx);
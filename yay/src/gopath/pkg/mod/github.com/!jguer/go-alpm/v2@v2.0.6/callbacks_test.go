@@ -1,6 +1,12 @@
+//go:build cgo
+// +build cgo
+
 package alpm
 
 import (
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -23,3 +29,173 @@ func TestCallbacks(t *testing.T) {
 		panic(nil)
 	}
 }
+
+func TestTransactionCallbacks(t *testing.T) {
+	h := initTestRoot(t)
+	events := []Event{}
+
+	h.SetEventCallback(func(ctx interface{}, e Event) {
+		events := ctx.(*[]Event)
+		*events = append(*events, e)
+	}, &events)
+	h.SetProgressCallback(func(ctx interface{}, p Progress, pkg string, percent int, howmany, current uint) {
+		if percent < 0 || percent > 100 || current > howmany {
+			t.Errorf("invalid progress %d %d/%d", percent, current, howmany)
+		}
+	}, nil)
+	h.SetDownloadCallback(func(ctx interface{}, filename string, e DownloadEvent) {
+		if e == nil {
+			t.Errorf("unknown download event for %s", filename)
+		}
+	}, nil)
+	h.SetFetchCallback(func(ctx interface{}, url, localpath string, force bool) int {
+		return -1
+	}, nil)
+
+	if err := h.TransInit(TransFlagDBOnly); err != nil {
+		t.Fatalf("TransInit failed: %s", err)
+	}
+	if err := h.TransPrepare(); err != nil {
+		t.Errorf("TransPrepare failed: %s", err)
+	}
+	if err := h.TransRelease(); err != nil {
+		t.Errorf("TransRelease failed: %s", err)
+	}
+
+	for _, e := range events {
+		if e.Type() < EventTypeCheckDepsStart || e.Type() > EventTypeHookRunDone {
+			t.Errorf("invalid event type %d", e.Type())
+		}
+	}
+}
+
+func TestDownloadEventTypes(t *testing.T) {
+	tests := map[DownloadEventType]DownloadEvent{
+		DownloadEventTypeInit:      DownloadEventInit{},
+		DownloadEventTypeProgress:  DownloadEventProgress{},
+		DownloadEventTypeRetry:     DownloadEventRetry{},
+		DownloadEventTypeCompleted: DownloadEventCompleted{},
+	}
+
+	for want, e := range tests {
+		if e.Type() != want {
+			t.Errorf("%T has type %d, want %d", e, e.Type(), want)
+		}
+	}
+}
+
+// TestCallbacksParallel registers callbacks on many handles at once, run it
+// with -race to check the callback registry.
+func TestCallbacksParallel(t *testing.T) {
+	const handles = 16
+
+	var wg sync.WaitGroup
+	counts := make([]Cnt, handles)
+
+	for i := 0; i < handles; i++ {
+		root := t.TempDir()
+		dbpath := filepath.Join(root, "var/lib/pacman")
+		if err := os.MkdirAll(dbpath, 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		go func(cnt *Cnt) {
+			defer wg.Done()
+
+			h, err := Initialize(root, dbpath)
+			if err != nil {
+				t.Errorf("Failed at alpm initialization: %s", err)
+				return
+			}
+
+			h.SetLogCallback(func(ctx interface{}, lvl LogLevel, msg string) {
+				ctx.(*Cnt).cnt++
+			}, cnt)
+			h.SetEventCallback(func(ctx interface{}, e Event) {}, cnt)
+
+			if err := h.Release(); err != nil {
+				t.Errorf("Release failed: %s", err)
+			}
+		}(&counts[i])
+	}
+	wg.Wait()
+
+	for i, cnt := range counts {
+		if cnt.cnt != 1 {
+			t.Errorf("handle %d got %d log messages, want 1", i, cnt.cnt)
+		}
+	}
+
+	checkCallbacksReleased(t)
+}
+
+// TestCallbacksReleaseInitialize releases a handle while another one is
+// initialized, libalpm may allocate the new handle at the address of the
+// released one.
+func TestCallbacksReleaseInitialize(t *testing.T) {
+	const rounds = 32
+
+	root := t.TempDir()
+	dbpath := filepath.Join(root, "var/lib/pacman")
+	if err := os.MkdirAll(dbpath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	countLogs := func(ctx interface{}, lvl LogLevel, msg string) {
+		ctx.(*Cnt).cnt++
+	}
+
+	for i := 0; i < rounds; i++ {
+		old, err := Initialize(root, dbpath)
+		if err != nil {
+			t.Fatalf("Failed at alpm initialization: %s", err)
+		}
+		oldCnt, newCnt := &Cnt{}, &Cnt{}
+		old.SetLogCallback(countLogs, oldCnt)
+
+		var (
+			wg      sync.WaitGroup
+			h       *Handle
+			initErr error
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := old.Release(); err != nil {
+				t.Errorf("Release failed: %s", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if h, initErr = Initialize(root, dbpath); initErr == nil {
+				h.SetLogCallback(countLogs, newCnt)
+			}
+		}()
+		wg.Wait()
+
+		if initErr != nil {
+			t.Fatalf("Failed at alpm initialization: %s", initErr)
+		}
+		if err := h.Release(); err != nil {
+			t.Fatalf("Release failed: %s", err)
+		}
+
+		if oldCnt.cnt != 1 || newCnt.cnt != 1 {
+			t.Fatalf("round %d: released handle got %d log messages and new handle %d, want 1 each",
+				i, oldCnt.cnt, newCnt.cnt)
+		}
+	}
+
+	checkCallbacksReleased(t)
+}
+
+func checkCallbacksReleased(t *testing.T) {
+	t.Helper()
+
+	callbackRegistry.RLock()
+	defer callbackRegistry.RUnlock()
+	if len(callbackRegistry.handles) != 0 || len(callbackRegistry.tokens) != 0 {
+		t.Errorf("%d handles still have callbacks after Release", len(callbackRegistry.handles))
+	}
+}
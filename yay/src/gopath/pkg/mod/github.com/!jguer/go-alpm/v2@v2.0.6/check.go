@@ -0,0 +1,68 @@
+//go:build cgo
+// +build cgo
+
+// check.go - Consistency check of the local database.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import "strings"
+
+// LocalDBCheck holds the problems found in the local database.
+type LocalDBCheck struct {
+	MissingDeps []DepMissing
+	Conflicts   []Conflict
+	// FileConflicts holds files owned by two packages, as conflicts of
+	// type FileConflictTarget.
+	FileConflicts []FileConflict
+}
+
+// OK reports whether no problem was found.
+func (c *LocalDBCheck) OK() bool {
+	return len(c.MissingDeps) == 0 && len(c.Conflicts) == 0 && len(c.FileConflicts) == 0
+}
+
+// CheckLocalDB checks the local database like pacman -Dk: every dependency
+// must be installed, installed packages must not conflict with each other
+// and no file may be owned by more than one package. Nothing is modified.
+func (h *Handle) CheckLocalDB() (*LocalDBCheck, error) {
+	db, err := h.LocalDB()
+	if err != nil {
+		return nil, err
+	}
+	pkgs := db.PkgCache().Slice()
+
+	check := &LocalDBCheck{}
+	if check.MissingDeps, err = h.CheckDeps(pkgs, nil, pkgs, false); err != nil {
+		return nil, err
+	}
+	if check.Conflicts, err = h.CheckConflicts(pkgs); err != nil {
+		return nil, err
+	}
+
+	owners := map[string]string{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files() {
+			// directories are shared between packages
+			if strings.HasSuffix(file.Name, "/") {
+				continue
+			}
+
+			if owner, ok := owners[file.Name]; ok {
+				check.FileConflicts = append(check.FileConflicts, FileConflict{
+					Target:  owner,
+					Type:    FileConflictTarget,
+					File:    file.Name,
+					CTarget: pkg.Name(),
+				})
+				continue
+			}
+			owners[file.Name] = pkg.Name()
+		}
+	}
+
+	return check, nil
+}
@@ -0,0 +1,82 @@
+//go:build cgo
+// +build cgo
+
+// check_test.go - Tests for check.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import "testing"
+
+func TestCheckLocalDB(t *testing.T) {
+	h := initTestRoot(t)
+	installTestPkgs(t, h, 0,
+		testPkg{Name: "go-alpm-a", Version: "1.0-1", Files: map[string]string{"usr/bin/a": ""}},
+		testPkg{Name: "go-alpm-b", Version: "1.0-1", Depends: []string{"go-alpm-a"}},
+	)
+
+	check, err := h.CheckLocalDB()
+	if err != nil {
+		t.Fatalf("CheckLocalDB failed: %s", err)
+	}
+	if !check.OK() {
+		t.Errorf("consistent database has problems: %+v", check)
+	}
+
+	installTestPkgs(t, h, TransFlagNoDeps|TransFlagNoConflicts,
+		testPkg{
+			Name: "go-alpm-c", Version: "1.0-1",
+			Depends:   []string{"go-alpm-missing"},
+			Conflicts: []string{"go-alpm-b"},
+			Files:     map[string]string{"usr/bin/a": ""},
+		},
+	)
+
+	check, err = h.CheckLocalDB()
+	if err != nil {
+		t.Fatalf("CheckLocalDB failed: %s", err)
+	}
+	if len(check.MissingDeps) != 1 || check.MissingDeps[0].Target != "go-alpm-c" ||
+		check.MissingDeps[0].Depend.Name != "go-alpm-missing" {
+		t.Errorf("unexpected missing dependencies %v", check.MissingDeps)
+	}
+	if len(check.Conflicts) != 1 {
+		t.Errorf("unexpected conflicts %v", check.Conflicts)
+	}
+	if len(check.FileConflicts) != 1 || check.FileConflicts[0].String() !=
+		"go-alpm-a: usr/bin/a exists in both 'go-alpm-a' and 'go-alpm-c'" {
+		t.Errorf("unexpected file conflicts %v", check.FileConflicts)
+	}
+}
+
+func TestSetReason(t *testing.T) {
+	h := initTestRoot(t)
+	installTestPkgs(t, h, 0, testPkg{Name: "go-alpm-test", Version: "1.0-1"})
+
+	db, _ := h.LocalDB()
+	pkg := db.Pkg("go-alpm-test").(*Package)
+	if pkg.Reason() != PkgReasonExplicit {
+		t.Fatalf("got reason %s, want explicit", pkg.Reason())
+	}
+
+	if err := h.TransInit(0); err != nil {
+		t.Fatalf("TransInit failed: %s", err)
+	}
+	defer h.TransRelease()
+
+	if err := pkg.SetReason(PkgReasonDepend); err != nil {
+		t.Fatalf("SetReason failed: %s", err)
+	}
+	if pkg.Reason() != PkgReasonDepend {
+		t.Errorf("got reason %s, want dependency", pkg.Reason())
+	}
+
+	// loaded package archives do not belong to the local database
+	file := loadTestPkgs(t, h, testPkg{Name: "go-alpm-file", Version: "1.0-1"})[0].(*Package)
+	if err := file.SetReason(PkgReasonDepend); err == nil {
+		t.Errorf("SetReason on a package file should fail")
+	}
+}
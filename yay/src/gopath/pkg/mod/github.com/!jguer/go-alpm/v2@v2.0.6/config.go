@@ -0,0 +1,148 @@
+//go:build cgo
+// +build cgo
+
+// config.go - Configure a handle from pacman.conf.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"fmt"
+
+	"github.com/Morganamilo/go-pacmanconf"
+)
+
+// ParseUsage parses the Usage values of a pacman.conf repository. No value
+// means UsageAll.
+func ParseUsage(values []string) (Usage, error) {
+	if len(values) == 0 {
+		return UsageAll, nil
+	}
+
+	var usage Usage
+	for _, value := range values {
+		switch value {
+		case "Sync":
+			usage |= UsageSync
+		case "Search":
+			usage |= UsageSearch
+		case "Install":
+			usage |= UsageInstall
+		case "Upgrade":
+			usage |= UsageUpgrade
+		case "All":
+			usage |= UsageAll
+		default:
+			return 0, fmt.Errorf("invalid value for Usage: %s", value)
+		}
+	}
+
+	return usage, nil
+}
+
+// ApplyConfig configures the handle from a parsed pacman.conf and registers
+// its repositories as sync databases, with their servers, usage and
+// signature level. The root and database paths are set by Initialize and
+// the hook directories are added to the system one.
+//
+// UseDelta is ignored as libalpm no longer supports delta upgrades, and
+// XferCommand has to be implemented with SetFetchCallback.
+func (h *Handle) ApplyConfig(conf *pacmanconf.Config) error {
+	sigLevel, err := ParseSigLevel(DefaultSigLevel, conf.SigLevel...)
+	if err != nil {
+		return err
+	}
+	localSigLevel, err := ParseSigLevel(sigLevel, conf.LocalFileSigLevel...)
+	if err != nil {
+		return err
+	}
+	remoteSigLevel, err := ParseSigLevel(sigLevel, conf.RemoteFileSigLevel...)
+	if err != nil {
+		return err
+	}
+
+	if err := h.SetDefaultSigLevel(sigLevel); err != nil {
+		return err
+	}
+	if err := h.SetLocalFileSigLevel(localSigLevel); err != nil {
+		return err
+	}
+	if err := h.SetRemoteFileSigLevel(remoteSigLevel); err != nil {
+		return err
+	}
+
+	if err := h.SetCacheDirs(conf.CacheDir); err != nil {
+		return err
+	}
+	for _, dir := range conf.HookDir {
+		if err := h.AddHookDir(dir); err != nil {
+			return err
+		}
+	}
+	if conf.GPGDir != "" {
+		if err := h.SetGPGDir(conf.GPGDir); err != nil {
+			return err
+		}
+	}
+	if conf.LogFile != "" {
+		if err := h.SetLogFile(conf.LogFile); err != nil {
+			return err
+		}
+	}
+
+	if err := h.SetIgnorePkgs(conf.IgnorePkg); err != nil {
+		return err
+	}
+	if err := h.SetIgnoreGroups(conf.IgnoreGroup); err != nil {
+		return err
+	}
+	if err := h.SetArchitectures(conf.Architecture); err != nil {
+		return err
+	}
+	if err := h.SetNoUpgrades(conf.NoUpgrade); err != nil {
+		return err
+	}
+	if err := h.SetNoExtracts(conf.NoExtract); err != nil {
+		return err
+	}
+	if err := h.SetUseSyslog(conf.UseSyslog); err != nil {
+		return err
+	}
+	if err := h.SetCheckSpace(conf.CheckSpace); err != nil {
+		return err
+	}
+	if err := h.SetDisableDLTimeout(conf.DisableDownloadTimeout); err != nil {
+		return err
+	}
+
+	for _, repo := range conf.Repos {
+		if err := h.registerRepo(repo, sigLevel); err != nil {
+			return fmt.Errorf("repository %s: %w", repo.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (h *Handle) registerRepo(repo pacmanconf.Repository, defaultSigLevel SigLevel) error {
+	sigLevel, err := ParseSigLevel(defaultSigLevel, repo.SigLevel...)
+	if err != nil {
+		return err
+	}
+	usage, err := ParseUsage(repo.Usage)
+	if err != nil {
+		return err
+	}
+
+	db, err := h.RegisterSyncDB(repo.Name, sigLevel)
+	if err != nil {
+		return err
+	}
+	db.SetServers(repo.Servers)
+	db.SetUsage(usage)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+//go:build cgo
+// +build cgo
+
+// config_test.go - Tests for config.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Morganamilo/go-pacmanconf"
+)
+
+func TestParseUsage(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    Usage
+		wantErr bool
+	}{
+		{nil, UsageAll, false},
+		{[]string{"All"}, UsageAll, false},
+		{[]string{"Sync", "Search"}, UsageSync | UsageSearch, false},
+		{[]string{"Install", "Upgrade"}, UsageInstall | UsageUpgrade, false},
+		{[]string{"Sync", "Everything"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseUsage(tt.values)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error %v", tt.values, err)
+		}
+		if got != tt.want {
+			t.Errorf("%v: got %d, want %d", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	h := initTestRoot(t)
+	root, _ := h.Root()
+
+	conf := &pacmanconf.Config{
+		CacheDir:     []string{root + "/var/cache/pacman/pkg/"},
+		IgnorePkg:    []string{"linux"},
+		Architecture: []string{"x86_64"},
+		NoUpgrade:    []string{"etc/pacman.conf"},
+		SigLevel:     []string{"Required", "DatabaseOptional"},
+		CheckSpace:   true,
+		Repos: []pacmanconf.Repository{
+			{Name: "core", Servers: []string{"https://example.org/core/os/x86_64"}},
+			{Name: "testing", Usage: []string{"Sync", "Search"}, SigLevel: []string{"PackageNever"}},
+		},
+	}
+
+	if err := h.ApplyConfig(conf); err != nil {
+		t.Fatalf("ApplyConfig failed: %s", err)
+	}
+
+	level, _ := h.GetDefaultSigLevel()
+	if want := SigPackage | SigDatabase | SigDatabaseOptional; level != want {
+		t.Errorf("got default siglevel %d, want %d", level, want)
+	}
+	if ignore, _ := h.IgnorePkgs(); !reflect.DeepEqual(ignore.Slice(), conf.IgnorePkg) {
+		t.Errorf("got IgnorePkg %v", ignore.Slice())
+	}
+	if checkSpace, _ := h.CheckSpace(); !checkSpace {
+		t.Errorf("CheckSpace was not set")
+	}
+
+	dbs, _ := h.SyncDBs()
+	names := []string{}
+	for _, db := range dbs.Slice() {
+		names = append(names, db.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"core", "testing"}) {
+		t.Fatalf("got sync databases %v", names)
+	}
+	if servers := dbs.Slice()[0].Servers(); !reflect.DeepEqual(servers, conf.Repos[0].Servers) {
+		t.Errorf("got servers %v", servers)
+	}
+
+	bad := &pacmanconf.Config{Repos: []pacmanconf.Repository{{Name: "extra", Usage: []string{"Never"}}}}
+	if err := h.ApplyConfig(bad); err == nil {
+		t.Errorf("ApplyConfig with an invalid usage should fail")
+	}
+}
@@ -104,7 +104,7 @@ func (db *DB) Name() string {
 // Servers returns host server URL.
 func (db *DB) Servers() []string {
 	ptr := unsafe.Pointer(C.alpm_db_get_servers(db.ptr))
-	return StringList{(*list)(ptr)}.Slice()
+	return convertStringList((*list)(ptr)).Slice()
 }
 
 // SetServers sets server list to use.
@@ -173,3 +173,50 @@ func (db *DB) Search(targets []string) IPackageList {
 	C.alpm_list_free(needles)
 	return PackageList{(*list)(unsafe.Pointer(ret)), db.handle}
 }
+
+// DBUpdateResult holds the outcome of refreshing a single database.
+type DBUpdateResult struct {
+	DB     IDB
+	Status DBUpdateStatus
+	Err    error
+}
+
+// DBUpdate downloads the given sync databases from their servers. Databases
+// are refreshed one at a time so that each gets its own result, progress is
+// reported through the download and event callbacks. If force is true the
+// databases are downloaded even if they are up to date.
+func (h *Handle) DBUpdate(dbs IDBList, force bool) []DBUpdateResult {
+	cForce := C.int(0)
+	if force {
+		cForce = C.int(1)
+	}
+
+	results := []DBUpdateResult{}
+	_ = dbs.ForEach(func(idb IDB) error {
+		result := DBUpdateResult{DB: idb, Status: DBUpdateFailed}
+
+		db, ok := idb.(*DB)
+		if !ok {
+			result.Err = fmt.Errorf("database %s is not an alpm database", idb.Name())
+			results = append(results, result)
+			return nil
+		}
+
+		dbList := C.alpm_list_add(nil, unsafe.Pointer(db.ptr))
+		defer C.alpm_list_free(dbList)
+
+		switch C.alpm_db_update(h.ptr, dbList, cForce) {
+		case 0:
+			result.Status = DBUpdated
+		case 1:
+			result.Status = DBUpToDate
+		default:
+			result.Err = h.LastError()
+		}
+
+		results = append(results, result)
+		return nil
+	})
+
+	return results
+}
@@ -0,0 +1,144 @@
+//go:build cgo
+// +build cgo
+
+// db_test.go - Tests for db.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestSyncDB writes an uncompressed sync database named name.db holding
+// pkgs into dir. The file lists of the packages are included, so the
+// database can be renamed to name.files.
+func writeTestSyncDB(t *testing.T, dir, name string, pkgs ...testPkg) {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(dir, name+".db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	for _, pkg := range pkgs {
+		desc := &strings.Builder{}
+		section := func(key string, values ...string) {
+			if len(values) > 0 {
+				fmt.Fprintf(desc, "%%%s%%\n%s\n\n", key, strings.Join(values, "\n"))
+			}
+		}
+
+		section("FILENAME", pkg.Name+"-"+pkg.Version+"-any.pkg.tar")
+		section("NAME", pkg.Name)
+		section("BASE", pkg.Name)
+		section("VERSION", pkg.Version)
+		section("DESC", "go-alpm test package")
+		section("ARCH", "any")
+		section("BUILDDATE", "1627862400")
+		section("PACKAGER", "go-alpm tests")
+		section("DEPENDS", pkg.Depends...)
+		section("PROVIDES", pkg.Provides...)
+		section("CONFLICTS", pkg.Conflicts...)
+		section("REPLACES", pkg.Replaces...)
+		if pkg.ISize > 0 {
+			section("ISIZE", fmt.Sprint(pkg.ISize))
+		}
+
+		write := func(name, content string) {
+			hdr := &tar.Header{
+				Name: pkg.Name + "-" + pkg.Version + "/" + name, Mode: 0o644,
+				Size: int64(len(content)), ModTime: time.Unix(1627862400, 0),
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		write("desc", desc.String())
+		if len(pkg.Files) > 0 {
+			files := make([]string, 0, len(pkg.Files))
+			for file := range pkg.Files {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+			write("files", "%FILES%\n"+strings.Join(files, "\n")+"\n\n")
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDBUpdate(t *testing.T) {
+	h := initTestRoot(t)
+	mirror := t.TempDir()
+	writeTestSyncDB(t, mirror, "test", testPkg{Name: "go-alpm-test", Version: "1.0-1"})
+
+	db, err := h.RegisterSyncDB("test", 0)
+	if err != nil {
+		t.Fatalf("RegisterSyncDB failed: %s", err)
+	}
+	db.SetServers([]string{"file://" + mirror})
+
+	if _, err := h.RegisterSyncDB("noserver", 0); err != nil {
+		t.Fatalf("RegisterSyncDB failed: %s", err)
+	}
+
+	downloads := 0
+	h.SetDownloadCallback(func(ctx interface{}, filename string, e DownloadEvent) {
+		if e.Type() == DownloadEventTypeCompleted {
+			downloads++
+		}
+	}, nil)
+
+	dbs, _ := h.SyncDBs()
+	tests := []struct {
+		name  string
+		force bool
+		want  []DBUpdateStatus
+	}{
+		{name: "first update", want: []DBUpdateStatus{DBUpdated, DBUpdateFailed}},
+		{name: "up to date", want: []DBUpdateStatus{DBUpToDate, DBUpdateFailed}},
+		{name: "forced", force: true, want: []DBUpdateStatus{DBUpdated, DBUpdateFailed}},
+	}
+
+	for _, tt := range tests {
+		results := h.DBUpdate(dbs, tt.force)
+		if len(results) != len(tt.want) {
+			t.Fatalf("%s: got %d results, want %d", tt.name, len(results), len(tt.want))
+		}
+
+		for i, result := range results {
+			if result.Status != tt.want[i] {
+				t.Errorf("%s: %s is %s, want %s", tt.name, result.DB.Name(), result.Status, tt.want[i])
+			}
+			if (result.Status == DBUpdateFailed) != (result.Err != nil) {
+				t.Errorf("%s: %s has status %s and error %v", tt.name, result.DB.Name(), result.Status, result.Err)
+			}
+		}
+	}
+
+	if db.Pkg("go-alpm-test") == nil {
+		t.Errorf("package missing from the refreshed database")
+	}
+	if downloads == 0 {
+		t.Errorf("download callback was not called")
+	}
+}
@@ -6,6 +6,7 @@ package alpm
 import "C"
 
 import (
+	"errors"
 	"fmt"
 	"unsafe"
 )
@@ -43,3 +44,76 @@ func (l PackageList) FindSatisfier(depstring string) (IPackage, error) {
 
 	return &Package{ptr, l.handle}, nil
 }
+
+// cPkgList builds an alpm_list_t of the packages, which must be alpm
+// packages. The list must be freed with alpm_list_free.
+func cPkgList(pkgs []IPackage) (*C.alpm_list_t, error) {
+	var l *C.alpm_list_t
+	for _, pkg := range pkgs {
+		alpmPkg, ok := pkg.(*Package)
+		if !ok {
+			C.alpm_list_free(l)
+			return nil, errors.New("package is not an alpm package")
+		}
+		l = C.alpm_list_add(l, unsafe.Pointer(alpmPkg.pmpkg))
+	}
+	return l, nil
+}
+
+// CheckDeps checks the dependencies of the packages in upgrade against pkgs,
+// usually the local database, once the packages in remove are removed and the
+// ones in upgrade are installed. If reverse is set, the packages of pkgs whose
+// dependencies would break once remove and upgrade are applied are reported
+// as well.
+func (h *Handle) CheckDeps(pkgs, remove, upgrade []IPackage, reverse bool) ([]DepMissing, error) {
+	cLists := make([]*C.alpm_list_t, 3)
+	for i, pkgs := range [][]IPackage{pkgs, remove, upgrade} {
+		l, err := cPkgList(pkgs)
+		if err != nil {
+			return nil, err
+		}
+		defer C.alpm_list_free(l)
+		cLists[i] = l
+	}
+
+	cReverse := C.int(0)
+	if reverse {
+		cReverse = C.int(1)
+	}
+
+	data := C.alpm_checkdeps(h.ptr, cLists[0], cLists[1], cLists[2], cReverse)
+	defer C.alpm_list_free(data)
+
+	missing := []DepMissing{}
+	_ = (*list)(unsafe.Pointer(data)).forEach(func(p unsafe.Pointer) error {
+		miss := (*C.alpm_depmissing_t)(p)
+		missing = append(missing, convertDepMissing(miss))
+		C.alpm_depmissing_free(miss)
+		return nil
+	})
+
+	return missing, nil
+}
+
+// CheckConflicts returns the conflicts between the packages of pkgs. Like
+// alpm_checkconflicts, pkgs are not checked against the local database.
+func (h *Handle) CheckConflicts(pkgs []IPackage) ([]Conflict, error) {
+	l, err := cPkgList(pkgs)
+	if err != nil {
+		return nil, err
+	}
+	defer C.alpm_list_free(l)
+
+	data := C.alpm_checkconflicts(h.ptr, l)
+	defer C.alpm_list_free(data)
+
+	conflicts := []Conflict{}
+	_ = (*list)(unsafe.Pointer(data)).forEach(func(p unsafe.Pointer) error {
+		conflict := (*C.alpm_conflict_t)(p)
+		conflicts = append(conflicts, convertConflict(conflict))
+		C.alpm_conflict_free(conflict)
+		return nil
+	})
+
+	return conflicts, nil
+}
@@ -0,0 +1,83 @@
+//go:build cgo
+// +build cgo
+
+// deps_test.go - Tests for deps.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import "testing"
+
+// loadTestPkgs writes and loads the packages.
+func loadTestPkgs(t *testing.T, h *Handle, pkgs ...testPkg) []IPackage {
+	t.Helper()
+
+	dir := t.TempDir()
+	loaded := make([]IPackage, len(pkgs))
+	for i, pkg := range pkgs {
+		p, err := h.LoadPkg(writeTestPkg(t, dir, pkg), true, 0)
+		if err != nil {
+			t.Fatalf("LoadPkg failed: %s", err)
+		}
+		t.Cleanup(func() { _ = p.(*Package).Free() })
+		loaded[i] = p
+	}
+
+	return loaded
+}
+
+func TestCheckDeps(t *testing.T) {
+	h := initTestRoot(t)
+	pkgs := loadTestPkgs(t, h,
+		testPkg{Name: "go-alpm-test", Version: "1.0-1", Depends: []string{"go-alpm-dep>=2"}},
+		testPkg{Name: "go-alpm-provider", Version: "1.0-1", Provides: []string{"go-alpm-dep=2.1"}},
+	)
+
+	missing, err := h.CheckDeps(nil, nil, pkgs[:1], false)
+	if err != nil {
+		t.Fatalf("CheckDeps failed: %s", err)
+	}
+	if len(missing) != 1 || missing[0].Target != "go-alpm-test" || missing[0].Depend.String() != "go-alpm-dep>=2" {
+		t.Errorf("unexpected missing dependencies %v", missing)
+	}
+
+	missing, err = h.CheckDeps(nil, nil, pkgs, false)
+	if err != nil {
+		t.Fatalf("CheckDeps failed: %s", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("provider was not taken into account: %v", missing)
+	}
+
+	if _, err := h.CheckDeps([]IPackage{nil}, nil, nil, true); err == nil {
+		t.Errorf("CheckDeps should fail on non alpm packages")
+	}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	h := initTestRoot(t)
+	pkgs := loadTestPkgs(t, h,
+		testPkg{Name: "go-alpm-test", Version: "1.0-1", Conflicts: []string{"go-alpm-other"}},
+		testPkg{Name: "go-alpm-other", Version: "1.0-1"},
+		testPkg{Name: "go-alpm-unrelated", Version: "1.0-1"},
+	)
+
+	conflicts, err := h.CheckConflicts(pkgs)
+	if err != nil {
+		t.Fatalf("CheckConflicts failed: %s", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Reason.Name != "go-alpm-other" {
+		t.Errorf("unexpected conflicts %v", conflicts)
+	}
+
+	conflicts, err = h.CheckConflicts(pkgs[1:])
+	if err != nil {
+		t.Fatalf("CheckConflicts failed: %s", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("unexpected conflicts %v", conflicts)
+	}
+}
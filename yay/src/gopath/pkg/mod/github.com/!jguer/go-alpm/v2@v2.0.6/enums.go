@@ -33,6 +33,14 @@ const (
 	FromSyncDB
 )
 
+// Type of a file conflict.
+type FileConflictType uint
+
+const (
+	FileConflictTarget     FileConflictType = iota + 1 // Conflict between two targets.
+	FileConflictFilesystem                             // Conflict with a file on disk.
+)
+
 // Dependency constraint types.
 type DepMod uint
 
@@ -79,6 +87,9 @@ const (
 )
 const SigUseDefault SigLevel = 1 << 30
 
+// DefaultSigLevel is the level pacman uses when SigLevel is not configured.
+const DefaultSigLevel = SigPackage | SigPackageOptional | SigDatabase | SigDatabaseOptional
+
 // Signature status
 type SigStatus int
 
@@ -88,8 +99,51 @@ const (
 	SigStatusSigExpired
 	SigStatusKeyUnknown
 	SigStatusKeyDisabled
+	SigStatusInvalid
 )
 
+func (s SigStatus) String() string {
+	switch s {
+	case SigStatusValid:
+		return "valid"
+	case SigStatusKeyExpired:
+		return "key expired"
+	case SigStatusSigExpired:
+		return "signature expired"
+	case SigStatusKeyUnknown:
+		return "key unknown"
+	case SigStatusKeyDisabled:
+		return "key disabled"
+	case SigStatusInvalid:
+		return "invalid"
+	}
+	return ""
+}
+
+// Signature validity
+type SigValidity int
+
+const (
+	SigValidityFull SigValidity = iota
+	SigValidityMarginal
+	SigValidityNever
+	SigValidityUnknown
+)
+
+func (v SigValidity) String() string {
+	switch v {
+	case SigValidityFull:
+		return "full trust"
+	case SigValidityMarginal:
+		return "marginal trust"
+	case SigValidityNever:
+		return "never trust"
+	case SigValidityUnknown:
+		return "unknown trust"
+	}
+	return ""
+}
+
 type LogLevel uint16
 
 // Logging levels.
@@ -156,3 +210,238 @@ const (
 	TransFlagRecurseAll
 	TransFlagNoLock
 )
+
+type EventType uint
+
+const (
+	EventTypeCheckDepsStart EventType = iota + 1
+	EventTypeCheckDepsDone
+	EventTypeFileConflictsStart
+	EventTypeFileConflictsDone
+	EventTypeResolveDepsStart
+	EventTypeResolveDepsDone
+	EventTypeInterConflictsStart
+	EventTypeInterConflictsDone
+	EventTypeTransactionStart
+	EventTypeTransactionDone
+	EventTypePackageOperationStart
+	EventTypePackageOperationDone
+	EventTypeIntegrityStart
+	EventTypeIntegrityDone
+	EventTypeLoadStart
+	EventTypeLoadDone
+	EventTypeScriptletInfo
+	EventTypeDBRetrieveStart
+	EventTypeDBRetrieveDone
+	EventTypeDBRetrieveFailed
+	EventTypePkgRetrieveStart
+	EventTypePkgRetrieveDone
+	EventTypePkgRetrieveFailed
+	EventTypeDiskSpaceStart
+	EventTypeDiskSpaceDone
+	EventTypeOptDepRemoval
+	EventTypeDatabaseMissing
+	EventTypeKeyringStart
+	EventTypeKeyringDone
+	EventTypeKeyDownloadStart
+	EventTypeKeyDownloadDone
+	EventTypePacnewCreated
+	EventTypePacsaveCreated
+	EventTypeHookStart
+	EventTypeHookDone
+	EventTypeHookRunStart
+	EventTypeHookRunDone
+)
+
+// Operation performed on a package during a transaction.
+type PackageOperation uint
+
+const (
+	PackageOperationInstall PackageOperation = iota + 1
+	PackageOperationUpgrade
+	PackageOperationReinstall
+	PackageOperationDowngrade
+	PackageOperationRemove
+)
+
+func (op PackageOperation) String() string {
+	switch op {
+	case PackageOperationInstall:
+		return "install"
+	case PackageOperationUpgrade:
+		return "upgrade"
+	case PackageOperationReinstall:
+		return "reinstall"
+	case PackageOperationDowngrade:
+		return "downgrade"
+	case PackageOperationRemove:
+		return "remove"
+	}
+	return ""
+}
+
+// Transaction stage at which a hook runs.
+type HookWhen uint
+
+const (
+	HookPreTransaction HookWhen = iota + 1
+	HookPostTransaction
+)
+
+// Progress type reported by the progress callback.
+type Progress uint
+
+const (
+	ProgressAddStart Progress = iota
+	ProgressUpgradeStart
+	ProgressDowngradeStart
+	ProgressReinstallStart
+	ProgressRemoveStart
+	ProgressConflictsStart
+	ProgressDiskspaceStart
+	ProgressIntegrityStart
+	ProgressLoadStart
+	ProgressKeyringStart
+)
+
+type DownloadEventType uint
+
+const (
+	DownloadEventTypeInit DownloadEventType = iota
+	DownloadEventTypeProgress
+	DownloadEventTypeRetry
+	DownloadEventTypeCompleted
+)
+
+// Result of a database refresh.
+type DBUpdateStatus int
+
+const (
+	DBUpdated DBUpdateStatus = iota
+	DBUpToDate
+	DBUpdateFailed
+)
+
+func (s DBUpdateStatus) String() string {
+	switch s {
+	case DBUpdated:
+		return "updated"
+	case DBUpToDate:
+		return "up to date"
+	case DBUpdateFailed:
+		return "failed"
+	}
+	return ""
+}
+
+// HoldReason tells why a sysupgrade plan holds a package back.
+type HoldReason int
+
+const (
+	HoldIgnored      HoldReason = iota + 1 // The package is in IgnorePkg or IgnoreGroup.
+	HoldHeld                               // The package was held by the caller.
+	HoldNewerLocally                       // The installed version is newer.
+)
+
+func (r HoldReason) String() string {
+	switch r {
+	case HoldIgnored:
+		return "ignored"
+	case HoldHeld:
+		return "held"
+	case HoldNewerLocally:
+		return "newer locally"
+	}
+	return ""
+}
+
+// SearchField is a set of package fields matched by a search.
+type SearchField uint
+
+const (
+	SearchName SearchField = 1 << iota
+	SearchDescription
+	SearchProvides
+
+	SearchAll = SearchName | SearchDescription | SearchProvides
+)
+
+func (f SearchField) String() string {
+	s := ""
+	for _, field := range []struct {
+		flag SearchField
+		name string
+	}{{SearchName, "name"}, {SearchDescription, "description"}, {SearchProvides, "provides"}} {
+		if f&field.flag == 0 {
+			continue
+		}
+		if s != "" {
+			s += ","
+		}
+		s += field.name
+	}
+	return s
+}
+
+// BackupStatus is the state of a backup file on disk.
+type BackupStatus int
+
+const (
+	BackupUnmodified BackupStatus = iota + 1 // The file matches the package.
+	BackupModified                           // The file was changed.
+	BackupMissing                            // The file does not exist.
+	BackupUnreadable                         // The file could not be read.
+)
+
+func (s BackupStatus) String() string {
+	switch s {
+	case BackupUnmodified:
+		return "unmodified"
+	case BackupModified:
+		return "modified"
+	case BackupMissing:
+		return "missing"
+	case BackupUnreadable:
+		return "unreadable"
+	}
+	return ""
+}
+
+// DiscrepancyKind is the way an installed file differs from its package.
+type DiscrepancyKind int
+
+const (
+	FileMissing          DiscrepancyKind = iota + 1 // The file does not exist.
+	FileUnreadable                                  // The file could not be read.
+	FileTypeMismatch                                // The file type differs.
+	FileModeMismatch                                // The permissions differ.
+	FileOwnerMismatch                               // The owning user or group differs.
+	FileMTimeMismatch                               // The modification time differs.
+	FileSizeMismatch                                // The size differs.
+	FileLinkMismatch                                // The symlink target differs.
+	FileChecksumMismatch                            // The content differs.
+)
+
+func (k DiscrepancyKind) String() string {
+	switch k {
+	case FileMissing:
+		return "missing"
+	case FileUnreadable:
+		return "unreadable"
+	case FileTypeMismatch:
+		return "type mismatch"
+	case FileModeMismatch:
+		return "permissions mismatch"
+	case FileOwnerMismatch:
+		return "owner mismatch"
+	case FileMTimeMismatch:
+		return "modification time mismatch"
+	case FileSizeMismatch:
+		return "size mismatch"
+	case FileLinkMismatch:
+		return "symlink path mismatch"
+	case FileChecksumMismatch:
+		return "checksum mismatch"
+	}
+	return ""
+}
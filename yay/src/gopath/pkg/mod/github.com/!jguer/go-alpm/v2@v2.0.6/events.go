@@ -0,0 +1,230 @@
+// events.go - libalpm event and download event types.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+// #include <alpm.h>
+import "C"
+
+import "unsafe"
+
+// Event is implemented by every event passed to the event callback.
+// Packages referenced by an event are only valid during the callback.
+type Event interface {
+	Type() EventType
+}
+
+// EventAny is passed for events that carry no data besides their type.
+type EventAny struct {
+	EventType EventType
+}
+
+func (e EventAny) Type() EventType {
+	return e.EventType
+}
+
+// EventPackageOperation is passed when a package is installed, upgraded,
+// reinstalled, downgraded or removed. OldPkg is nil on install and NewPkg
+// is nil on removal.
+type EventPackageOperation struct {
+	EventAny
+	Operation PackageOperation
+	OldPkg    IPackage
+	NewPkg    IPackage
+}
+
+// EventOptDepRemoval is passed when a package that is an optional
+// dependency of Pkg is removed.
+type EventOptDepRemoval struct {
+	EventAny
+	Pkg    IPackage
+	OptDep Depend
+}
+
+// EventScriptletInfo is passed for every line printed by an install scriptlet.
+type EventScriptletInfo struct {
+	EventAny
+	Line string
+}
+
+// EventDatabaseMissing is passed when a sync database file is missing.
+type EventDatabaseMissing struct {
+	EventAny
+	DBName string
+}
+
+// EventPkgRetrieve is passed before and after packages are downloaded.
+type EventPkgRetrieve struct {
+	EventAny
+	Num       uint
+	TotalSize int64
+}
+
+// EventPacnewCreated is passed when a .pacnew file is written.
+type EventPacnewCreated struct {
+	EventAny
+	FromNoUpgrade bool
+	OldPkg        IPackage
+	NewPkg        IPackage
+	File          string
+}
+
+// EventPacsaveCreated is passed when a .pacsave file is written.
+type EventPacsaveCreated struct {
+	EventAny
+	OldPkg IPackage
+	File   string
+}
+
+// EventHook is passed when the pre or post transaction hooks start and end.
+type EventHook struct {
+	EventAny
+	When HookWhen
+}
+
+// EventHookRun is passed when a single hook starts and ends.
+type EventHookRun struct {
+	EventAny
+	Name     string
+	Desc     string
+	Position uint
+	Total    uint
+}
+
+// eventPackage wraps a possibly NULL package pointer, keeping the interface nil
+// for NULL.
+func eventPackage(ptr *C.alpm_pkg_t, h Handle) IPackage {
+	if ptr == nil {
+		return nil
+	}
+	return &Package{ptr, h}
+}
+
+func convertEvent(event *C.alpm_event_t, h Handle) Event {
+	base := EventAny{EventType((*C.alpm_event_any_t)(unsafe.Pointer(event))._type)}
+	p := unsafe.Pointer(event)
+
+	switch base.EventType {
+	case EventTypePackageOperationStart, EventTypePackageOperationDone:
+		e := (*C.alpm_event_package_operation_t)(p)
+		return EventPackageOperation{
+			EventAny:  base,
+			Operation: PackageOperation(e.operation),
+			OldPkg:    eventPackage(e.oldpkg, h),
+			NewPkg:    eventPackage(e.newpkg, h),
+		}
+	case EventTypeOptDepRemoval:
+		e := (*C.alpm_event_optdep_removal_t)(p)
+		return EventOptDepRemoval{
+			EventAny: base,
+			Pkg:      eventPackage(e.pkg, h),
+			OptDep:   convertDepend(e.optdep),
+		}
+	case EventTypeScriptletInfo:
+		e := (*C.alpm_event_scriptlet_info_t)(p)
+		return EventScriptletInfo{EventAny: base, Line: C.GoString(e.line)}
+	case EventTypeDatabaseMissing:
+		e := (*C.alpm_event_database_missing_t)(p)
+		return EventDatabaseMissing{EventAny: base, DBName: C.GoString(e.dbname)}
+	case EventTypePkgRetrieveStart, EventTypePkgRetrieveDone, EventTypePkgRetrieveFailed:
+		e := (*C.alpm_event_pkg_retrieve_t)(p)
+		return EventPkgRetrieve{EventAny: base, Num: uint(e.num), TotalSize: int64(e.total_size)}
+	case EventTypePacnewCreated:
+		e := (*C.alpm_event_pacnew_created_t)(p)
+		return EventPacnewCreated{
+			EventAny:      base,
+			FromNoUpgrade: e.from_noupgrade != 0,
+			OldPkg:        eventPackage(e.oldpkg, h),
+			NewPkg:        eventPackage(e.newpkg, h),
+			File:          C.GoString(e.file),
+		}
+	case EventTypePacsaveCreated:
+		e := (*C.alpm_event_pacsave_created_t)(p)
+		return EventPacsaveCreated{
+			EventAny: base,
+			OldPkg:   eventPackage(e.oldpkg, h),
+			File:     C.GoString(e.file),
+		}
+	case EventTypeHookStart, EventTypeHookDone:
+		e := (*C.alpm_event_hook_t)(p)
+		return EventHook{EventAny: base, When: HookWhen(e.when)}
+	case EventTypeHookRunStart, EventTypeHookRunDone:
+		e := (*C.alpm_event_hook_run_t)(p)
+		return EventHookRun{
+			EventAny: base,
+			Name:     C.GoString(e.name),
+			Desc:     C.GoString(e.desc),
+			Position: uint(e.position),
+			Total:    uint(e.total),
+		}
+	}
+
+	return base
+}
+
+// DownloadEvent is implemented by every event passed to the download callback.
+type DownloadEvent interface {
+	Type() DownloadEventType
+}
+
+// DownloadEventInit is passed when a download starts.
+type DownloadEventInit struct {
+	Optional bool
+}
+
+func (DownloadEventInit) Type() DownloadEventType {
+	return DownloadEventTypeInit
+}
+
+// DownloadEventProgress is passed while a file is being downloaded.
+type DownloadEventProgress struct {
+	Downloaded int64
+	Total      int64
+}
+
+func (DownloadEventProgress) Type() DownloadEventType {
+	return DownloadEventTypeProgress
+}
+
+// DownloadEventRetry is passed when a download is retried from another
+// server. Resume is true if the download continues the partial file.
+type DownloadEventRetry struct {
+	Resume bool
+}
+
+func (DownloadEventRetry) Type() DownloadEventType {
+	return DownloadEventTypeRetry
+}
+
+// DownloadEventCompleted is passed when a download finishes. Result is 0 on
+// success, 1 if the file was already up to date and -1 on error.
+type DownloadEventCompleted struct {
+	Total  int64
+	Result int
+}
+
+func (DownloadEventCompleted) Type() DownloadEventType {
+	return DownloadEventTypeCompleted
+}
+
+func convertDownloadEvent(event C.alpm_download_event_type_t, data unsafe.Pointer) DownloadEvent {
+	switch DownloadEventType(event) {
+	case DownloadEventTypeInit:
+		e := (*C.alpm_download_event_init_t)(data)
+		return DownloadEventInit{Optional: e.optional != 0}
+	case DownloadEventTypeProgress:
+		e := (*C.alpm_download_event_progress_t)(data)
+		return DownloadEventProgress{Downloaded: int64(e.downloaded), Total: int64(e.total)}
+	case DownloadEventTypeRetry:
+		e := (*C.alpm_download_event_retry_t)(data)
+		return DownloadEventRetry{Resume: e.resume != 0}
+	case DownloadEventTypeCompleted:
+		e := (*C.alpm_download_event_completed_t)(data)
+		return DownloadEventCompleted{Total: int64(e.total), Result: int(e.result)}
+	}
+
+	return nil
+}
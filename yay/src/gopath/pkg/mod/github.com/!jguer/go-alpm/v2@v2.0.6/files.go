@@ -0,0 +1,205 @@
+//go:build cgo
+// +build cgo
+
+// files.go - File ownership and file database queries.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileMatch is a file of a package matched by a query. File is relative to
+// the root, directories end with a slash.
+type FileMatch struct {
+	DB      IDB
+	Package IPackage
+	File    string
+}
+
+// Owner returns the installed packages owning name, like pacman -Qo. name
+// is either absolute or relative to the current directory and must be
+// inside the root. A directory can be owned by several packages, a file by
+// one, and no package is returned for unowned paths.
+func (h *Handle) Owner(name string) ([]IPackage, error) {
+	rel, err := h.rootRelative(name)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.LocalDB()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []IPackage{}
+	for _, pkg := range db.PkgCache().Slice() {
+		if _, err := pkg.ContainsFile(rel); err == nil {
+			owners = append(owners, pkg)
+		}
+	}
+
+	return owners, nil
+}
+
+// rootRelative turns name into a path relative to the root, as found in
+// package file lists, with a trailing slash for directories.
+func (h *Handle) rootRelative(name string) (string, error) {
+	root, err := h.Root()
+	if err != nil {
+		return "", err
+	}
+
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "../") || rel == ".." {
+		return "", fmt.Errorf("%s is not inside the root %s", name, root)
+	}
+
+	if info, err := os.Lstat(abs); err == nil && info.IsDir() {
+		rel += "/"
+	}
+
+	return rel, nil
+}
+
+// fileMatcher builds the matcher of a files query. Like pacman -F, a pattern
+// containing a slash is matched against the whole path, ignoring a leading
+// slash, and other patterns against the file name. Regular expressions are
+// case insensitive.
+func fileMatcher(pattern string, regex bool) (func(string) bool, error) {
+	fullPath := strings.Contains(pattern, "/")
+	if fullPath {
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	match := func(s string) bool { return s == pattern }
+	if regex {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, err
+		}
+		match = re.MatchString
+	}
+
+	if fullPath {
+		return match, nil
+	}
+
+	return func(file string) bool {
+		if strings.HasSuffix(file, "/") {
+			return false
+		}
+		return match(path.Base(file))
+	}, nil
+}
+
+// FilesSearch searches the file lists of the packages of dbs, like pacman
+// -F or -Fx with regex set. The file lists of sync databases are only
+// available with SetDBExt(".files") set before the databases are registered
+// and updated.
+func FilesSearch(dbs IDBList, pattern string, regex bool) ([]FileMatch, error) {
+	match, err := fileMatcher(pattern, regex)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []FileMatch{}
+	for _, db := range dbs.Slice() {
+		for _, pkg := range db.PkgCache().Slice() {
+			for _, file := range pkg.Files() {
+				if match(file.Name) {
+					matches = append(matches, FileMatch{db, pkg, file.Name})
+				}
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// FileIndex is a prebuilt index of the file lists of databases, to answer
+// repeated queries without going through libalpm. The index is not updated
+// when the databases change.
+type FileIndex struct {
+	files  []FileMatch
+	byPath map[string][]int
+	byName map[string][]int
+}
+
+// NewFileIndex indexes the files of the packages of dbs.
+func NewFileIndex(dbs ...IDB) *FileIndex {
+	idx := &FileIndex{
+		byPath: map[string][]int{},
+		byName: map[string][]int{},
+	}
+
+	for _, db := range dbs {
+		for _, pkg := range db.PkgCache().Slice() {
+			for _, file := range pkg.Files() {
+				i := len(idx.files)
+				idx.files = append(idx.files, FileMatch{db, pkg, file.Name})
+				idx.byPath[file.Name] = append(idx.byPath[file.Name], i)
+				if !strings.HasSuffix(file.Name, "/") {
+					name := path.Base(file.Name)
+					idx.byName[name] = append(idx.byName[name], i)
+				}
+			}
+		}
+	}
+
+	return idx
+}
+
+// Owners returns the packages holding name, which is relative to the root
+// and ends with a slash for directories.
+func (idx *FileIndex) Owners(name string) []FileMatch {
+	return idx.lookup(idx.byPath, name)
+}
+
+// Search works like FilesSearch on the indexed databases. Plain patterns
+// are looked up in the index, regular expressions are matched against
+// every indexed file.
+func (idx *FileIndex) Search(pattern string, regex bool) ([]FileMatch, error) {
+	if !regex {
+		if strings.Contains(pattern, "/") {
+			return idx.lookup(idx.byPath, strings.TrimPrefix(pattern, "/")), nil
+		}
+		return idx.lookup(idx.byName, pattern), nil
+	}
+
+	match, err := fileMatcher(pattern, regex)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []FileMatch{}
+	for _, file := range idx.files {
+		if match(file.File) {
+			matches = append(matches, file)
+		}
+	}
+
+	return matches, nil
+}
+
+func (idx *FileIndex) lookup(m map[string][]int, key string) []FileMatch {
+	matches := make([]FileMatch, len(m[key]))
+	for i, j := range m[key] {
+		matches[i] = idx.files[j]
+	}
+	return matches
+}
@@ -0,0 +1,119 @@
+//go:build cgo
+// +build cgo
+
+// files_test.go - Tests for files.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOwner(t *testing.T) {
+	h := initTestRoot(t)
+	root, _ := h.Root()
+	installTestPkgs(t, h, 0,
+		testPkg{Name: "go-alpm-test", Version: "1.0-1", Files: map[string]string{
+			"usr/bin/go-alpm-test": "#!/bin/sh\n",
+		}},
+		testPkg{Name: "go-alpm-other", Version: "1.0-1", Files: map[string]string{
+			"usr/share/go-alpm-other/data": "data\n",
+		}},
+	)
+
+	owners, err := h.Owner(filepath.Join(root, "usr/bin/go-alpm-test"))
+	if err != nil {
+		t.Fatalf("Owner failed: %s", err)
+	}
+	if len(owners) != 1 || owners[0].Name() != "go-alpm-test" {
+		t.Errorf("unexpected owners %v", owners)
+	}
+
+	if owners, err := h.Owner(filepath.Join(root, "usr/bin/unowned")); err != nil || len(owners) != 0 {
+		t.Errorf("unowned file has owners %v, %v", owners, err)
+	}
+	if _, err := h.Owner(filepath.Dir(root)); err == nil {
+		t.Errorf("Owner outside of the root should fail")
+	}
+}
+
+func TestFilesSearch(t *testing.T) {
+	h := initTestRoot(t)
+	mirror := t.TempDir()
+	writeTestSyncDB(t, mirror, "test",
+		testPkg{Name: "yay", Version: "11.0-1", Files: map[string]string{
+			"usr/bin/yay": "", "usr/share/doc/yay/README": "",
+		}},
+		testPkg{Name: "paru", Version: "1.7-1", Files: map[string]string{
+			"usr/bin/paru": "", "usr/share/doc/paru/README": "",
+		}},
+	)
+	if err := os.Rename(filepath.Join(mirror, "test.db"), filepath.Join(mirror, "test.files")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.SetDBExt(".files"); err != nil {
+		t.Fatal(err)
+	}
+	db, err := h.RegisterSyncDB("test", 0)
+	if err != nil {
+		t.Fatalf("RegisterSyncDB failed: %s", err)
+	}
+	db.SetServers([]string{"file://" + mirror})
+	dbs, _ := h.SyncDBs()
+	if res := h.DBUpdate(dbs, false); res[0].Err != nil {
+		t.Fatalf("DBUpdate failed: %s", res[0].Err)
+	}
+
+	// packages are sorted by name in the database
+	idx := NewFileIndex(dbs.Slice()...)
+
+	tests := []struct {
+		pattern string
+		regex   bool
+		want    []string
+	}{
+		{"yay", false, []string{"yay usr/bin/yay"}},
+		{"README", false, []string{"paru usr/share/doc/paru/README", "yay usr/share/doc/yay/README"}},
+		{"/usr/bin/paru", false, []string{"paru usr/bin/paru"}},
+		{"usr/bin", false, []string{}},
+		{"^(YAY|paru)$", true, []string{"paru usr/bin/paru", "yay usr/bin/yay"}},
+		{"^usr/share/.*/paru/", true, []string{"paru usr/share/doc/paru/README"}},
+	}
+
+	for _, tt := range tests {
+		matches, err := FilesSearch(dbs, tt.pattern, tt.regex)
+		if err != nil {
+			t.Fatalf("%s: FilesSearch failed: %s", tt.pattern, err)
+		}
+		indexed, err := idx.Search(tt.pattern, tt.regex)
+		if err != nil {
+			t.Fatalf("%s: FileIndex.Search failed: %s", tt.pattern, err)
+		}
+
+		for name, got := range map[string][]FileMatch{"FilesSearch": matches, "FileIndex": indexed} {
+			if len(got) != len(tt.want) {
+				t.Errorf("%s %q: got %d matches, want %d", name, tt.pattern, len(got), len(tt.want))
+				continue
+			}
+			for i, m := range got {
+				if s := m.Package.Name() + " " + m.File; s != tt.want[i] || m.DB.Name() != "test" {
+					t.Errorf("%s %q: got %s, want %s", name, tt.pattern, s, tt.want[i])
+				}
+			}
+		}
+	}
+
+	if _, err := FilesSearch(dbs, "(", true); err == nil {
+		t.Errorf("invalid regular expression should fail")
+	}
+	if owners := idx.Owners("usr/bin/yay"); len(owners) != 1 || owners[0].Package.Name() != "yay" {
+		t.Errorf("unexpected owners %v", owners)
+	}
+}
@@ -0,0 +1,546 @@
+// alpm.go - Use databases read from disk through the alpm interfaces.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package fsdb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// ErrSignatureUnsupported is returned by CheckPGPSignature, as checking
+// signatures needs libalpm and gpgme.
+var ErrSignatureUnsupported = errors.New("fsdb: signature checks are not supported")
+
+// errStop ends ForEach loops early.
+var errStop = errors.New("stop")
+
+// AlpmDB returns the database as a read-only alpm.IDB. Its packages report
+// "fsdb" as their Type and read their values from db, which must not be
+// modified while they are in use.
+func (db *DB) AlpmDB() alpm.IDB {
+	return &alpmDB{db: db, usage: alpm.UsageAll}
+}
+
+// AlpmDBList returns dbs as an alpm.IDBList, in order of priority, like
+// Handle.SyncDBs.
+func AlpmDBList(dbs ...*DB) alpm.IDBList {
+	l := &alpmDBList{}
+	for _, db := range dbs {
+		l.dbs = append(l.dbs, &alpmDB{db: db, usage: alpm.UsageAll, list: l})
+	}
+	return l
+}
+
+type alpmDB struct {
+	db      *DB
+	servers []string
+	usage   alpm.Usage
+
+	list *alpmDBList
+}
+
+var _ alpm.IDB = (*alpmDB)(nil)
+
+// Unregister removes the database from the list it belongs to.
+func (db *alpmDB) Unregister() error {
+	if db.list == nil {
+		return fmt.Errorf("database %s is not registered", db.db.Name)
+	}
+
+	dbs := db.list.dbs[:0]
+	for _, d := range db.list.dbs {
+		if d != db {
+			dbs = append(dbs, d)
+		}
+	}
+	db.list.dbs = dbs
+	db.list = nil
+
+	return nil
+}
+
+func (db *alpmDB) Name() string {
+	return db.db.Name
+}
+
+func (db *alpmDB) Servers() []string {
+	return db.servers
+}
+
+func (db *alpmDB) SetServers(servers []string) {
+	db.servers = servers
+}
+
+func (db *alpmDB) AddServer(server string) {
+	db.servers = append(db.servers, server)
+}
+
+func (db *alpmDB) SetUsage(usage alpm.Usage) {
+	db.usage = usage
+}
+
+func (db *alpmDB) Pkg(name string) alpm.IPackage {
+	if pkg := db.db.Pkg(name); pkg != nil {
+		return &alpmPackage{pkg, db}
+	}
+	return nil
+}
+
+func (db *alpmDB) PkgCache() alpm.IPackageList {
+	l := make(alpmPackageList, len(db.db.Packages))
+	for i, pkg := range db.db.Packages {
+		l[i] = &alpmPackage{pkg, db}
+	}
+	return l
+}
+
+// Search returns the packages matching every target, which are case
+// insensitive regular expressions matched against the name, description and
+// provisions of the packages. Like libalpm, an invalid expression returns
+// an empty list.
+func (db *alpmDB) Search(targets []string) alpm.IPackageList {
+	res := make([]*regexp.Regexp, len(targets))
+	for i, target := range targets {
+		re, err := regexp.Compile("(?i)" + target)
+		if err != nil {
+			return alpmPackageList{}
+		}
+		res[i] = re
+	}
+
+	l := alpmPackageList{}
+	for _, pkg := range db.db.Packages {
+		if matchesAll(pkg, res) {
+			l = append(l, &alpmPackage{pkg, db})
+		}
+	}
+	return l
+}
+
+func matchesAll(pkg *Package, res []*regexp.Regexp) bool {
+	for _, re := range res {
+		matched := re.MatchString(pkg.Name) || re.MatchString(pkg.Description)
+		for _, prov := range pkg.Provides {
+			matched = matched || re.MatchString(prov.Name)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPGPSignature returns ErrSignatureUnsupported.
+func (db *alpmDB) CheckPGPSignature() (alpm.SigList, error) {
+	return nil, ErrSignatureUnsupported
+}
+
+type alpmDBList struct {
+	dbs []*alpmDB
+}
+
+var _ alpm.IDBList = (*alpmDBList)(nil)
+
+func (l *alpmDBList) ForEach(f func(alpm.IDB) error) error {
+	for _, db := range l.dbs {
+		if err := f(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *alpmDBList) Slice() []alpm.IDB {
+	slice := make([]alpm.IDB, len(l.dbs))
+	for i, db := range l.dbs {
+		slice[i] = db
+	}
+	return slice
+}
+
+// FindGroupPkgs returns the packages of the group across the databases. A
+// package name is only returned once, from the first database holding it.
+func (l *alpmDBList) FindGroupPkgs(name string) alpm.IPackageList {
+	pkgs := alpmPackageList{}
+	seen := map[string]bool{}
+
+	for _, db := range l.dbs {
+		for _, pkg := range db.db.Packages {
+			if seen[pkg.Name] || !inGroup(pkg, name) {
+				continue
+			}
+			seen[pkg.Name] = true
+			pkgs = append(pkgs, &alpmPackage{pkg, db})
+		}
+	}
+
+	return pkgs
+}
+
+func inGroup(pkg *Package, group string) bool {
+	for _, g := range pkg.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// FindSatisfier resolves depstring the way libalpm does: a package with the
+// same name in the first database that has one wins over any provider, and
+// databases without install or upgrade usage are skipped.
+func (l *alpmDBList) FindSatisfier(depstring string) (alpm.IPackage, error) {
+	dep := vercmp.ParseDepend(depstring)
+
+	var dbs []*alpmDB
+	for _, db := range l.dbs {
+		if db.usage&(alpm.UsageInstall|alpm.UsageUpgrade) != 0 {
+			dbs = append(dbs, db)
+		}
+	}
+
+	for _, db := range dbs {
+		if pkg := db.db.Pkg(dep.Name); pkg != nil && satisfiesLiteral(pkg, dep) {
+			return &alpmPackage{pkg, db}, nil
+		}
+	}
+
+	for _, db := range dbs {
+		for _, pkg := range db.db.Packages {
+			if pkg.Name != dep.Name && satisfiesProvides(pkg, dep) {
+				return &alpmPackage{pkg, db}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unable to satisfy dependency %s in DBlist", depstring)
+}
+
+type alpmPackageList []*alpmPackage
+
+var _ alpm.IPackageList = alpmPackageList{}
+
+func (l alpmPackageList) ForEach(f func(alpm.IPackage) error) error {
+	for _, pkg := range l {
+		if err := f(pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l alpmPackageList) Slice() []alpm.IPackage {
+	slice := make([]alpm.IPackage, len(l))
+	for i, pkg := range l {
+		slice[i] = pkg
+	}
+	return slice
+}
+
+// SortBySize returns a copy of the list sorted by installed size, largest
+// first.
+func (l alpmPackageList) SortBySize() alpm.IPackageList {
+	sorted := append(alpmPackageList{}, l...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].pkg.ISize > sorted[j].pkg.ISize
+	})
+	return sorted
+}
+
+// FindSatisfier returns the first package of the list named after
+// depstring that satisfies it, or else the first provider.
+func (l alpmPackageList) FindSatisfier(depstring string) (alpm.IPackage, error) {
+	dep := vercmp.ParseDepend(depstring)
+
+	for _, pkg := range l {
+		if satisfiesLiteral(pkg.pkg, dep) {
+			return pkg, nil
+		}
+	}
+	for _, pkg := range l {
+		if satisfiesProvides(pkg.pkg, dep) {
+			return pkg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unable to find dependency %s in PackageList", depstring)
+}
+
+func satisfiesLiteral(pkg *Package, dep vercmp.Depend) bool {
+	return dep.Satisfies(pkg.Name, pkg.Version)
+}
+
+// satisfiesProvides reports whether one of the provisions of pkg satisfies
+// dep. Unversioned provisions only satisfy unversioned dependencies.
+func satisfiesProvides(pkg *Package, dep vercmp.Depend) bool {
+	for _, prov := range pkg.Provides {
+		version := prov.Version
+		if prov.Mod == vercmp.DepModAny {
+			version = ""
+		}
+		if dep.Satisfies(prov.Name, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// alpmPackage is a package of db seen through alpm.IPackage.
+type alpmPackage struct {
+	pkg *Package
+	db  *alpmDB
+}
+
+var _ alpm.IPackage = (*alpmPackage)(nil)
+
+// FileName returns the file name of a sync package, it is empty for local
+// packages.
+func (p *alpmPackage) FileName() string {
+	return p.pkg.FileName
+}
+
+// Base returns the package base, it is empty if the database has none.
+func (p *alpmPackage) Base() string {
+	return p.pkg.Base
+}
+
+func (p *alpmPackage) Base64Signature() string {
+	return p.pkg.PGPSig
+}
+
+func (p *alpmPackage) Validation() alpm.Validation {
+	var v alpm.Validation
+	for _, method := range p.pkg.Validation {
+		switch method {
+		case "none":
+			v |= alpm.ValidationNone
+		case "md5":
+			v |= alpm.ValidationMD5Sum
+		case "sha256":
+			v |= alpm.ValidationSHA256Sum
+		case "pgp":
+			v |= alpm.ValidationSignature
+		}
+	}
+	return v
+}
+
+// CheckPGPSignature returns ErrSignatureUnsupported.
+func (p *alpmPackage) CheckPGPSignature() (alpm.SigList, error) {
+	return nil, ErrSignatureUnsupported
+}
+
+func (p *alpmPackage) Architecture() string {
+	return p.pkg.Arch
+}
+
+func (p *alpmPackage) Backup() alpm.BackupList {
+	l := make(alpm.BackupList, len(p.pkg.Backup))
+	for i, backup := range p.pkg.Backup {
+		l[i] = alpm.BackupFile{Name: backup.Name, Hash: backup.Hash}
+	}
+	return l
+}
+
+func (p *alpmPackage) BuildDate() time.Time {
+	return p.pkg.BuildDate
+}
+
+func (p *alpmPackage) Conflicts() alpm.DependList {
+	return dependList(p.pkg.Conflicts)
+}
+
+func (p *alpmPackage) DB() alpm.IDB {
+	return p.db
+}
+
+func (p *alpmPackage) Depends() alpm.DependList {
+	return dependList(p.pkg.Depends)
+}
+
+func (p *alpmPackage) OptionalDepends() alpm.DependList {
+	return dependList(p.pkg.OptDepends)
+}
+
+func (p *alpmPackage) CheckDepends() alpm.DependList {
+	return dependList(p.pkg.CheckDepends)
+}
+
+func (p *alpmPackage) MakeDepends() alpm.DependList {
+	return dependList(p.pkg.MakeDepends)
+}
+
+func dependList(deps []vercmp.Depend) alpm.DependList {
+	l := make(alpm.DependList, len(deps))
+	for i, dep := range deps {
+		l[i] = alpm.Depend{
+			Name:        dep.Name,
+			Version:     dep.Version,
+			Description: dep.Description,
+			Mod:         alpm.DepMod(dep.Mod),
+		}
+	}
+	return l
+}
+
+func (p *alpmPackage) Description() string {
+	return p.pkg.Description
+}
+
+func (p *alpmPackage) Files() []alpm.File {
+	files := make([]alpm.File, len(p.pkg.Files))
+	for i, name := range p.pkg.Files {
+		files[i] = alpm.File{Name: name}
+	}
+	return files
+}
+
+func (p *alpmPackage) ContainsFile(path string) (alpm.File, error) {
+	for _, name := range p.pkg.Files {
+		if name == path {
+			return alpm.File{Name: name}, nil
+		}
+	}
+	return alpm.File{}, errors.New("no file")
+}
+
+func (p *alpmPackage) Groups() alpm.StringList {
+	return alpm.StringList(p.pkg.Groups)
+}
+
+func (p *alpmPackage) ISize() int64 {
+	return p.pkg.ISize
+}
+
+func (p *alpmPackage) InstallDate() time.Time {
+	return p.pkg.InstallDate
+}
+
+func (p *alpmPackage) Licenses() alpm.StringList {
+	return alpm.StringList(p.pkg.Licenses)
+}
+
+func (p *alpmPackage) SHA256Sum() string {
+	return p.pkg.SHA256Sum
+}
+
+func (p *alpmPackage) MD5Sum() string {
+	return p.pkg.MD5Sum
+}
+
+func (p *alpmPackage) Name() string {
+	return p.pkg.Name
+}
+
+func (p *alpmPackage) Packager() string {
+	return p.pkg.Packager
+}
+
+func (p *alpmPackage) Provides() alpm.DependList {
+	return dependList(p.pkg.Provides)
+}
+
+func (p *alpmPackage) Reason() alpm.PkgReason {
+	return alpm.PkgReason(p.pkg.Reason)
+}
+
+func (p *alpmPackage) Origin() alpm.PkgFrom {
+	if p.db.db.Local {
+		return alpm.FromLocalDB
+	}
+	return alpm.FromSyncDB
+}
+
+func (p *alpmPackage) Replaces() alpm.DependList {
+	return dependList(p.pkg.Replaces)
+}
+
+func (p *alpmPackage) Size() int64 {
+	return p.pkg.Size
+}
+
+func (p *alpmPackage) URL() string {
+	return p.pkg.URL
+}
+
+func (p *alpmPackage) Version() string {
+	return p.pkg.Version
+}
+
+// ComputeRequiredBy returns the names of the packages depending on the
+// package. Like libalpm, local packages are looked up in their database and
+// sync packages in every database of the list their database belongs to.
+func (p *alpmPackage) ComputeRequiredBy() []string {
+	return p.computeRequiredBy(func(pkg *Package) []vercmp.Depend { return pkg.Depends })
+}
+
+// ComputeOptionalFor returns the names of the packages optionally depending
+// on the package, looked up like ComputeRequiredBy.
+func (p *alpmPackage) ComputeOptionalFor() []string {
+	return p.computeRequiredBy(func(pkg *Package) []vercmp.Depend { return pkg.OptDepends })
+}
+
+func (p *alpmPackage) computeRequiredBy(deps func(*Package) []vercmp.Depend) []string {
+	dbs := []*alpmDB{p.db}
+	if !p.db.db.Local && p.db.list != nil {
+		dbs = nil
+		for _, db := range p.db.list.dbs {
+			if !db.db.Local {
+				dbs = append(dbs, db)
+			}
+		}
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, db := range dbs {
+		for _, pkg := range db.db.Packages {
+			for _, dep := range deps(pkg) {
+				if !seen[pkg.Name] && (satisfiesLiteral(p.pkg, dep) || satisfiesProvides(p.pkg, dep)) {
+					seen[pkg.Name] = true
+					names = append(names, pkg.Name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// ShouldIgnore returns false, the databases do not know the IgnorePkg
+// option of pacman.conf.
+func (p *alpmPackage) ShouldIgnore() bool {
+	return false
+}
+
+// SyncNewVersion returns the package of the same name from the first
+// database of l holding it if that package is newer, or nil.
+func (p *alpmPackage) SyncNewVersion(l alpm.IDBList) alpm.IPackage {
+	var spkg alpm.IPackage
+	_ = l.ForEach(func(db alpm.IDB) error {
+		spkg = db.Pkg(p.pkg.Name)
+		if spkg != nil {
+			return errStop
+		}
+		return nil
+	})
+
+	if spkg != nil && vercmp.Compare(spkg.Version(), p.pkg.Version) > 0 {
+		return spkg
+	}
+	return nil
+}
+
+func (p *alpmPackage) Type() string {
+	return "fsdb"
+}
@@ -0,0 +1,79 @@
+// alpm_test.go - Tests for the alpm interfaces of the fixtures.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package fsdb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+)
+
+func TestAlpmDB(t *testing.T) {
+	local, err := ReadLocal("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncDBs, err := ReadSyncDBs("testdata", "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var db alpm.IDB = local.AlpmDB()
+	dbs := AlpmDBList(syncDBs...)
+
+	pacman := db.Pkg("pacman")
+	if pacman == nil || pacman.Type() != "fsdb" || pacman.Origin() != alpm.FromLocalDB {
+		t.Fatalf("unexpected local pacman %v", pacman)
+	}
+	if got := pacman.Depends().Slice(); len(got) != 3 || got[1].String() != "glibc>=2.33" {
+		t.Errorf("unexpected depends %v", got)
+	}
+	if got := pacman.OptionalDepends().Slice(); len(got) != 1 || got[0].Description != "translate text with makepkg-template" {
+		t.Errorf("unexpected optional depends %v", got)
+	}
+	if got := pacman.Backup().Slice(); len(got) != 1 || got[0].Name != "etc/pacman.conf" {
+		t.Errorf("unexpected backup %v", got)
+	}
+	if pacman.Validation() != alpm.ValidationSignature || pacman.ISize() != 4739072 {
+		t.Errorf("unexpected validation %d or size %d", pacman.Validation(), pacman.ISize())
+	}
+	if _, err := pacman.ContainsFile("usr/bin/pacman"); err != nil {
+		t.Errorf("ContainsFile failed: %s", err)
+	}
+
+	if got := db.Pkg("glibc").ComputeRequiredBy(); !reflect.DeepEqual(got, []string{"pacman"}) {
+		t.Errorf("glibc is required by %v", got)
+	}
+	if db.Pkg("glibc").Reason() != alpm.PkgReasonDepend {
+		t.Errorf("glibc is not a dependency")
+	}
+
+	// only what the database holds is reported
+	if pacman.FileName() != "" || pacman.Base() != "pacman" || db.Pkg("glibc").Base() != "" {
+		t.Errorf("unexpected file name %q or bases %q %q", pacman.FileName(), pacman.Base(), db.Pkg("glibc").Base())
+	}
+	if sigs, err := pacman.CheckPGPSignature(); sigs != nil || !errors.Is(err, ErrSignatureUnsupported) {
+		t.Errorf("package signature check returned %v, %v", sigs, err)
+	}
+	if sigs, err := db.CheckPGPSignature(); sigs != nil || !errors.Is(err, ErrSignatureUnsupported) {
+		t.Errorf("database signature check returned %v, %v", sigs, err)
+	}
+
+	upgrade := pacman.SyncNewVersion(dbs)
+	if upgrade == nil || upgrade.Version() != "6.0.1-1" || upgrade.DB().Name() != "core" {
+		t.Fatalf("pacman upgrade not found")
+	}
+	if upgrade.FileName() != "pacman-6.0.1-1-x86_64.pkg.tar.zst" || upgrade.Size() != 920000 {
+		t.Errorf("unexpected sync pacman %s %d", upgrade.FileName(), upgrade.Size())
+	}
+
+	if sh, err := dbs.FindSatisfier("sh"); err != nil || sh.Name() != "zsh" {
+		t.Errorf("sh is not satisfied by zsh")
+	}
+}
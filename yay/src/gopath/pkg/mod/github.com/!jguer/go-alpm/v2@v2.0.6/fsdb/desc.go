@@ -0,0 +1,130 @@
+// desc.go - Parse the desc and files entries of pacman databases.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package fsdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// parseDesc reads an entry made of %SECTION% headers followed by values
+// and a blank line, such as desc, files or depends, into pkg.
+func parseDesc(r io.Reader, pkg *Package) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	section := ""
+
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" {
+			section = ""
+			continue
+		}
+
+		if section == "" {
+			if len(line) < 3 || !strings.HasPrefix(line, "%") || !strings.HasSuffix(line, "%") {
+				return fmt.Errorf("line %d: expected a section, got %q", n, line)
+			}
+			section = line[1 : len(line)-1]
+			continue
+		}
+
+		if err := pkg.setField(section, line); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (pkg *Package) setField(section, value string) error {
+	var err error
+
+	switch section {
+	case "NAME":
+		pkg.Name = value
+	case "VERSION":
+		pkg.Version = value
+	case "BASE":
+		pkg.Base = value
+	case "DESC":
+		pkg.Description = value
+	case "URL":
+		pkg.URL = value
+	case "ARCH":
+		pkg.Arch = value
+	case "PACKAGER":
+		pkg.Packager = value
+	case "FILENAME":
+		pkg.FileName = value
+	case "MD5SUM":
+		pkg.MD5Sum = value
+	case "SHA256SUM":
+		pkg.SHA256Sum = value
+	case "PGPSIG":
+		pkg.PGPSig = value
+	case "BUILDDATE":
+		pkg.BuildDate, err = parseDate(value)
+	case "INSTALLDATE":
+		pkg.InstallDate, err = parseDate(value)
+	case "CSIZE":
+		pkg.Size, err = strconv.ParseInt(value, 10, 64)
+	// the local database calls the installed size SIZE
+	case "ISIZE", "SIZE":
+		pkg.ISize, err = strconv.ParseInt(value, 10, 64)
+	case "REASON":
+		pkg.Reason, err = strconv.Atoi(value)
+	case "VALIDATION":
+		pkg.Validation = append(pkg.Validation, value)
+	case "GROUPS":
+		pkg.Groups = append(pkg.Groups, value)
+	case "LICENSE":
+		pkg.Licenses = append(pkg.Licenses, value)
+	case "DEPENDS":
+		pkg.Depends = append(pkg.Depends, vercmp.ParseDepend(value))
+	case "OPTDEPENDS":
+		pkg.OptDepends = append(pkg.OptDepends, vercmp.ParseDepend(value))
+	case "MAKEDEPENDS":
+		pkg.MakeDepends = append(pkg.MakeDepends, vercmp.ParseDepend(value))
+	case "CHECKDEPENDS":
+		pkg.CheckDepends = append(pkg.CheckDepends, vercmp.ParseDepend(value))
+	case "CONFLICTS":
+		pkg.Conflicts = append(pkg.Conflicts, vercmp.ParseDepend(value))
+	case "PROVIDES":
+		pkg.Provides = append(pkg.Provides, vercmp.ParseDepend(value))
+	case "REPLACES":
+		pkg.Replaces = append(pkg.Replaces, vercmp.ParseDepend(value))
+	case "FILES":
+		pkg.Files = append(pkg.Files, value)
+	case "BACKUP":
+		i := strings.LastIndex(value, "\t")
+		if i < 0 {
+			return fmt.Errorf("invalid backup entry %q", value)
+		}
+		pkg.Backup = append(pkg.Backup, Backup{value[:i], value[i+1:]})
+	}
+	// other sections, such as XDATA, are not used
+
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s", section, value)
+	}
+	return nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
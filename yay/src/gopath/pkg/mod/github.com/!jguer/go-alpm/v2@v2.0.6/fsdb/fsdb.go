@@ -0,0 +1,246 @@
+// fsdb.go - Read pacman databases without libalpm.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+// Package fsdb reads the local database and the sync databases of pacman
+// directly from the file system, in pure Go, so that it builds without cgo
+// and libalpm. The databases are read once and never written.
+//
+// The databases can also be used through the alpm interfaces, see DB.AlpmDB
+// and AlpmDBList.
+package fsdb
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// Package is a package read from a database.
+type Package struct {
+	Name        string
+	Version     string
+	Base        string
+	Description string
+	URL         string
+	Arch        string
+	Packager    string
+	// FileName, MD5Sum, SHA256Sum and PGPSig are only set in sync databases.
+	FileName  string
+	MD5Sum    string
+	SHA256Sum string
+	PGPSig    string
+
+	BuildDate   time.Time
+	InstallDate time.Time
+	// Size is the download size, only set in sync databases.
+	Size  int64
+	ISize int64
+	// Reason is 0 for explicitly installed packages and 1 for dependencies.
+	Reason int
+	// Validation holds how the package was validated on install, such as
+	// "pgp" or "sha256".
+	Validation []string
+
+	Groups       []string
+	Licenses     []string
+	Depends      []vercmp.Depend
+	OptDepends   []vercmp.Depend
+	MakeDepends  []vercmp.Depend
+	CheckDepends []vercmp.Depend
+	Conflicts    []vercmp.Depend
+	Provides     []vercmp.Depend
+	Replaces     []vercmp.Depend
+
+	// Files holds the paths of the files, relative to the root, with a
+	// trailing slash for directories. Sync databases only have file lists
+	// in their .files variant.
+	Files  []string
+	Backup []Backup
+}
+
+// Backup is a backup file of an installed package and the MD5 sum it was
+// installed with.
+type Backup struct {
+	Name string
+	Hash string
+}
+
+// DB is a database read from disk.
+type DB struct {
+	Name  string
+	Local bool
+	// Packages is sorted by name, like the package caches of libalpm.
+	Packages []*Package
+
+	byName map[string]*Package
+}
+
+func newDB(name string, local bool, pkgs []*Package) *DB {
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].Name < pkgs[j].Name
+	})
+
+	db := &DB{Name: name, Local: local, Packages: pkgs, byName: map[string]*Package{}}
+	for _, pkg := range pkgs {
+		db.byName[pkg.Name] = pkg
+	}
+	return db
+}
+
+// Pkg returns the package called name, or nil.
+func (db *DB) Pkg(name string) *Package {
+	return db.byName[name]
+}
+
+// ReadLocal reads the local database of dbPath, such as /var/lib/pacman,
+// with the file lists of the packages.
+func ReadLocal(dbPath string) (*DB, error) {
+	dir := filepath.Join(dbPath, "local")
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := []*Package{}
+	for _, info := range infos {
+		// skips ALPM_DB_VERSION
+		if !info.IsDir() {
+			continue
+		}
+
+		pkg := &Package{}
+		for _, entry := range []string{"desc", "files"} {
+			err := parseFile(filepath.Join(dir, info.Name(), entry), pkg)
+			if os.IsNotExist(err) && entry == "files" {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", info.Name(), err)
+			}
+		}
+
+		if pkg.Name == "" || pkg.Version == "" {
+			return nil, fmt.Errorf("%s: missing name or version", info.Name())
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	return newDB("local", true, pkgs), nil
+}
+
+func parseFile(name string, pkg *Package) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return parseDesc(f, pkg)
+}
+
+// ReadSyncDBs reads the sync databases called names from dbPath, such as
+// /var/lib/pacman, in order.
+func ReadSyncDBs(dbPath string, names ...string) ([]*DB, error) {
+	dbs := make([]*DB, len(names))
+	for i, name := range names {
+		db, err := ReadSync(name, filepath.Join(dbPath, "sync", name+".db"))
+		if err != nil {
+			return nil, err
+		}
+		dbs[i] = db
+	}
+	return dbs, nil
+}
+
+// ReadSync reads the sync database archive file, as downloaded from a
+// repository, under the given name. The archive may be compressed with
+// gzip or bzip2, or not compressed; zstd and xz are not supported.
+func ReadSync(name, file string) (*DB, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := decompress(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	byDir := map[string]*Package{}
+	pkgs := []*Package{}
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		dir, entry := path.Split(hdr.Name)
+		if dir == "" {
+			continue
+		}
+
+		pkg, ok := byDir[dir]
+		if !ok {
+			pkg = &Package{}
+			byDir[dir] = pkg
+			pkgs = append(pkgs, pkg)
+		}
+
+		switch entry {
+		case "desc", "files", "depends":
+			if err := parseDesc(tr, pkg); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", file, hdr.Name, err)
+			}
+		}
+	}
+
+	for dir, pkg := range byDir {
+		if pkg.Name == "" || pkg.Version == "" {
+			return nil, fmt.Errorf("%s: %s: missing name or version", file, dir)
+		}
+	}
+
+	return newDB(name, false, pkgs), nil
+}
+
+// decompress detects the compression of a database archive.
+func decompress(r *bufio.Reader) (io.Reader, error) {
+	magic, _ := r.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(r)
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return nil, fmt.Errorf("zstd compressed databases are not supported")
+	case bytes.Equal(magic, []byte{0xfd, 0x37, 0x7a, 0x58}):
+		return nil, fmt.Errorf("xz compressed databases are not supported")
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return bzip2.NewReader(r), nil
+	}
+
+	return r, nil
+}
@@ -0,0 +1,132 @@
+// fsdb_test.go - Tests for reading the database fixtures.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package fsdb
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+func TestReadLocal(t *testing.T) {
+	db, err := ReadLocal("testdata")
+	if err != nil {
+		t.Fatalf("ReadLocal failed: %s", err)
+	}
+
+	if !db.Local || db.Name != "local" || len(db.Packages) != 2 || db.Packages[0].Name != "glibc" {
+		t.Fatalf("unexpected database %+v", db)
+	}
+
+	pacman := db.Pkg("pacman")
+	if pacman == nil {
+		t.Fatal("pacman not found")
+	}
+
+	want := &Package{
+		Name:        "pacman",
+		Version:     "6.0.0-1",
+		Base:        "pacman",
+		Description: "A library-based package manager with dependency support",
+		URL:         "https://www.archlinux.org/pacman/",
+		Arch:        "x86_64",
+		Packager:    "Allan McRae <allan@archlinux.org>",
+		BuildDate:   time.Unix(1621600000, 0),
+		InstallDate: time.Unix(1621700000, 0),
+		ISize:       4739072,
+		Validation:  []string{"pgp"},
+		Groups:      []string{"base-devel"},
+		Licenses:    []string{"GPL"},
+		Depends: []vercmp.Depend{
+			{Name: "bash", Mod: vercmp.DepModAny},
+			{Name: "glibc", Version: "2.33", Mod: vercmp.DepModGE},
+			{Name: "libarchive", Mod: vercmp.DepModAny},
+		},
+		OptDepends: []vercmp.Depend{
+			{Name: "perl-locale-gettext", Description: "translate text with makepkg-template", Mod: vercmp.DepModAny},
+		},
+		Conflicts: []vercmp.Depend{{Name: "pacman-contrib", Version: "1.2.0", Mod: vercmp.DepModLT}},
+		Provides:  []vercmp.Depend{{Name: "libalpm.so", Version: "13-64", Mod: vercmp.DepModEq}},
+		Files:     []string{"etc/", "etc/pacman.conf", "usr/", "usr/bin/", "usr/bin/pacman"},
+		Backup:    []Backup{{"etc/pacman.conf", "7e5a4dc5a4e8a5f3e2b9ce4d1b3c7b9f"}},
+	}
+	if !reflect.DeepEqual(pacman, want) {
+		t.Errorf("got %+v\nwant %+v", pacman, want)
+	}
+
+	glibc := db.Pkg("glibc")
+	if glibc.Reason != 1 || !reflect.DeepEqual(glibc.Validation, []string{"sha256", "pgp"}) || glibc.Files != nil {
+		t.Errorf("unexpected glibc %+v", glibc)
+	}
+}
+
+func TestReadSyncDBs(t *testing.T) {
+	dbs, err := ReadSyncDBs("testdata", "core")
+	if err != nil {
+		t.Fatalf("ReadSyncDBs failed: %s", err)
+	}
+
+	core := dbs[0]
+	if core.Name != "core" || core.Local {
+		t.Errorf("unexpected database %s", core.Name)
+	}
+
+	names := []string{}
+	for _, pkg := range core.Packages {
+		names = append(names, pkg.Name+"-"+pkg.Version)
+	}
+	if want := []string{"glibc-2.33-5", "pacman-6.0.1-1", "zsh-5.8-1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+
+	pacman := core.Pkg("pacman")
+	if pacman.FileName != "pacman-6.0.1-1-x86_64.pkg.tar.zst" || pacman.Size != 920000 || pacman.ISize != 4750000 ||
+		pacman.PGPSig != "iQEzBAABCAAdFiEE" || len(pacman.MakeDepends) != 1 || len(pacman.CheckDepends) != 1 {
+		t.Errorf("unexpected pacman %+v", pacman)
+	}
+	if zsh := core.Pkg("zsh"); len(zsh.Replaces) != 1 || zsh.Replaces[0].String() != "zsh-legacy<5" {
+		t.Errorf("unexpected zsh replaces %v", zsh.Replaces)
+	}
+
+	if _, err := ReadSyncDBs("testdata", "extra"); err == nil {
+		t.Errorf("missing database did not fail")
+	}
+}
+
+func TestParseDescErrors(t *testing.T) {
+	tests := []struct {
+		desc string
+		err  string
+	}{
+		{"NAME\nfoo\n", "expected a section"},
+		{"%SIZE%\nbig\n", "invalid SIZE"},
+		{"%BACKUP%\netc/foo.conf\n", "invalid backup entry"},
+	}
+
+	for _, tt := range tests {
+		err := parseDesc(strings.NewReader(tt.desc), &Package{})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: got %v, want %q", tt.desc, err, tt.err)
+		}
+	}
+}
+
+func TestReadSyncUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "core.db")
+	if err := ioutil.WriteFile(path, []byte{0x28, 0xb5, 0x2f, 0xfd, 0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadSync("core", path); err == nil || !strings.Contains(err.Error(), "zstd") {
+		t.Errorf("got %v, want a zstd error", err)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/Jguer/go-alpm/v2
 
 go 1.15
+
+require github.com/Morganamilo/go-pacmanconf v0.0.0-20210502114700-cff030e927a5
@@ -0,0 +1,93 @@
+// export.go - Export dependency graphs to DOT and JSON.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// WriteDOT writes the graph in the Graphviz DOT language. Optional
+// dependencies are dashed, missing dependencies red, and edges are labelled
+// with the dependency when it is not just the name of the target.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph dependencies {\n")
+
+	for _, n := range g.Nodes() {
+		bw.WriteString("\t" + strconv.Quote(n.Name))
+		if n.Missing() {
+			bw.WriteString(" [color=red, style=dashed]")
+		} else {
+			bw.WriteString(" [label=" + strconv.Quote(n.Name+"\n"+n.Package.Version()) + "]")
+		}
+		bw.WriteString(";\n")
+	}
+
+	for _, e := range g.Edges() {
+		bw.WriteString("\t" + strconv.Quote(e.From) + " -> " + strconv.Quote(e.To))
+
+		attrs := ""
+		if dep := e.Depend.String(); dep != e.To {
+			attrs = "label=" + strconv.Quote(dep)
+		}
+		if e.Kind == OptionalDepends {
+			if attrs != "" {
+				attrs += ", "
+			}
+			attrs += "style=dashed"
+		}
+		if attrs != "" {
+			bw.WriteString(" [" + attrs + "]")
+		}
+		bw.WriteString(";\n")
+	}
+
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+type jsonNode struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	DB      string `json:"db,omitempty"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+type jsonEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Depend string `json:"depend"`
+	Kind   string `json:"kind"`
+}
+
+// MarshalJSON encodes the graph as an object with a list of nodes, sorted
+// by name, and a list of edges.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Nodes []jsonNode `json:"nodes"`
+		Edges []jsonEdge `json:"edges"`
+	}{Nodes: []jsonNode{}, Edges: []jsonEdge{}}
+
+	for _, n := range g.Nodes() {
+		node := jsonNode{Name: n.Name, Missing: n.Missing()}
+		if !n.Missing() {
+			node.Version = n.Package.Version()
+			if db := n.Package.DB(); db != nil {
+				node.DB = db.Name()
+			}
+		}
+		out.Nodes = append(out.Nodes, node)
+	}
+	for _, e := range g.Edges() {
+		out.Edges = append(out.Edges, jsonEdge{e.From, e.To, e.Depend.String(), e.Kind.String()})
+	}
+
+	return json.Marshal(out)
+}
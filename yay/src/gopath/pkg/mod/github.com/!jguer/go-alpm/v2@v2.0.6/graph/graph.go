@@ -0,0 +1,365 @@
+// graph.go - Dependency graphs of packages.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+// Package graph builds the dependency graph of packages, like pactree, to
+// walk it in either direction, find cycles and export it.
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+)
+
+// EdgeKind is the kind of dependency an edge stands for.
+type EdgeKind int
+
+const (
+	Depends         EdgeKind = iota + 1 // A dependency.
+	OptionalDepends                     // An optional dependency.
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case Depends:
+		return "depends"
+	case OptionalDepends:
+		return "optdepends"
+	}
+	return ""
+}
+
+// Node is a package of the graph. Dependencies that could not be resolved
+// are nodes without a package, named after the dependency string.
+type Node struct {
+	Name    string
+	Package alpm.IPackage
+}
+
+// Missing reports whether the node is an unresolved dependency.
+func (n *Node) Missing() bool {
+	return n.Package == nil
+}
+
+// Edge goes from a package to the node satisfying one of its dependencies.
+type Edge struct {
+	From   string
+	To     string
+	Depend alpm.Depend
+	Kind   EdgeKind
+}
+
+// Options configures how a graph is built.
+type Options struct {
+	// Optional adds the optional dependencies. Unresolved optional
+	// dependencies are left out rather than added as missing nodes.
+	Optional bool
+}
+
+// Graph is a dependency graph. Nodes are identified by package name.
+type Graph struct {
+	nodes map[string]*Node
+	out   map[string][]Edge
+	in    map[string][]Edge
+}
+
+func newGraph() *Graph {
+	return &Graph{
+		nodes: map[string]*Node{},
+		out:   map[string][]Edge{},
+		in:    map[string][]Edge{},
+	}
+}
+
+// FromDB returns the graph of every package of db, such as the local
+// database, with dependencies resolved within db.
+func FromDB(db alpm.IDB, opts Options) *Graph {
+	cache := db.PkgCache()
+	g := newGraph()
+	g.build(cache.Slice(), cache.FindSatisfier, opts)
+	return g
+}
+
+// FromTargets returns the graph of targets and their dependencies, resolved
+// against dbs like libalpm would. Targets are dependency strings, such as
+// package names, and every one must be satisfiable.
+func FromTargets(dbs alpm.IDBList, targets []string, opts Options) (*Graph, error) {
+	roots := make([]alpm.IPackage, 0, len(targets))
+	for _, target := range targets {
+		pkg, err := dbs.FindSatisfier(target)
+		if err != nil {
+			return nil, fmt.Errorf("target not found: %s", target)
+		}
+		roots = append(roots, pkg)
+	}
+
+	g := newGraph()
+	g.build(roots, dbs.FindSatisfier, opts)
+	return g, nil
+}
+
+func (g *Graph) build(roots []alpm.IPackage, resolve func(string) (alpm.IPackage, error), opts Options) {
+	queue := []alpm.IPackage{}
+	for _, pkg := range roots {
+		if g.addPkg(pkg) {
+			queue = append(queue, pkg)
+		}
+	}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		kinds := []EdgeKind{Depends}
+		if opts.Optional {
+			kinds = append(kinds, OptionalDepends)
+		}
+
+		for _, kind := range kinds {
+			deps := pkg.Depends()
+			if kind == OptionalDepends {
+				deps = pkg.OptionalDepends()
+			}
+
+			for _, dep := range deps.Slice() {
+				to, err := resolve(dep.String())
+				var name string
+				switch {
+				case err == nil:
+					name = to.Name()
+					if g.addPkg(to) {
+						queue = append(queue, to)
+					}
+				case kind == OptionalDepends:
+					continue
+				default:
+					name = dep.String()
+					if g.nodes[name] == nil {
+						g.nodes[name] = &Node{Name: name}
+					}
+				}
+
+				g.addEdge(Edge{pkg.Name(), name, dep, kind})
+			}
+		}
+	}
+}
+
+// addPkg adds pkg to the graph and reports whether it was not there.
+func (g *Graph) addPkg(pkg alpm.IPackage) bool {
+	if g.nodes[pkg.Name()] != nil {
+		return false
+	}
+	g.nodes[pkg.Name()] = &Node{Name: pkg.Name(), Package: pkg}
+	return true
+}
+
+func (g *Graph) addEdge(e Edge) {
+	g.out[e.From] = append(g.out[e.From], e)
+	g.in[e.To] = append(g.in[e.To], e)
+}
+
+// Node returns the node called name, or nil.
+func (g *Graph) Node(name string) *Node {
+	return g.nodes[name]
+}
+
+// Nodes returns the nodes of the graph, sorted by name.
+func (g *Graph) Nodes() []*Node {
+	nodes := make([]*Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+// Edges returns the edges of the graph, sorted by source. The edges of a
+// package are in the order of its dependencies.
+func (g *Graph) Edges() []Edge {
+	edges := []Edge{}
+	for _, n := range g.Nodes() {
+		edges = append(edges, g.out[n.Name]...)
+	}
+	return edges
+}
+
+// Dependencies returns the edges leaving name.
+func (g *Graph) Dependencies(name string) []Edge {
+	return append([]Edge{}, g.out[name]...)
+}
+
+// Dependents returns the edges reaching name, like ComputeRequiredBy and
+// ComputeOptionalFor restricted to the graph.
+func (g *Graph) Dependents(name string) []Edge {
+	return append([]Edge{}, g.in[name]...)
+}
+
+// TraverseOptions configures Traverse.
+type TraverseOptions struct {
+	// Reverse follows the edges backwards, to the packages depending on the
+	// root, like pactree -r.
+	Reverse bool
+	// Optional follows optional dependencies too.
+	Optional bool
+	// MaxDepth stops the traversal at that distance from the root, 0 means
+	// no limit.
+	MaxDepth int
+}
+
+// Visit is a node reached by Traverse. Edge is nil for the root.
+type Visit struct {
+	Node  *Node
+	Depth int
+	Edge  *Edge
+}
+
+// Traverse walks the graph depth first from root, visiting every reachable
+// node once, in the order pactree -u prints them.
+func (g *Graph) Traverse(root string, opts TraverseOptions) ([]Visit, error) {
+	if g.nodes[root] == nil {
+		return nil, fmt.Errorf("%s is not in the graph", root)
+	}
+
+	visits := []Visit{}
+	seen := map[string]bool{root: true}
+
+	var walk func(name string, depth int)
+	walk = func(name string, depth int) {
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			return
+		}
+
+		for _, e := range g.follow(name, opts) {
+			e := e
+			next := e.To
+			if opts.Reverse {
+				next = e.From
+			}
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			visits = append(visits, Visit{g.nodes[next], depth + 1, &e})
+			walk(next, depth+1)
+		}
+	}
+
+	visits = append(visits, Visit{Node: g.nodes[root]})
+	walk(root, 0)
+
+	return visits, nil
+}
+
+// follow returns the edges Traverse may follow from name.
+func (g *Graph) follow(name string, opts TraverseOptions) []Edge {
+	edges := g.out[name]
+	if opts.Reverse {
+		edges = g.in[name]
+	}
+
+	followed := []Edge{}
+	for _, e := range edges {
+		if e.Kind == Depends || opts.Optional {
+			followed = append(followed, e)
+		}
+	}
+	return followed
+}
+
+// Subgraph returns the part of the graph Traverse reaches from root, with
+// the edges it may follow between the reached nodes.
+func (g *Graph) Subgraph(root string, opts TraverseOptions) (*Graph, error) {
+	visits, err := g.Traverse(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := newGraph()
+	for _, v := range visits {
+		sub.nodes[v.Node.Name] = v.Node
+	}
+	for _, v := range visits {
+		if opts.MaxDepth > 0 && v.Depth >= opts.MaxDepth {
+			continue
+		}
+		for _, e := range g.follow(v.Node.Name, opts) {
+			if sub.nodes[e.From] != nil && sub.nodes[e.To] != nil {
+				sub.addEdge(e)
+			}
+		}
+	}
+
+	return sub, nil
+}
+
+// Cycles returns the dependency cycles of the graph, as the names of the
+// packages of each cycle, sorted. A package depending on itself is a cycle
+// too.
+func (g *Graph) Cycles() [][]string {
+	// Tarjan's strongly connected components
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	cycles := [][]string{}
+
+	var connect func(name string)
+	connect = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		selfLoop := false
+		for _, e := range g.out[name] {
+			if e.To == name {
+				selfLoop = true
+			}
+			if _, ok := index[e.To]; !ok {
+				connect(e.To)
+				if low[e.To] < low[name] {
+					low[name] = low[e.To]
+				}
+			} else if onStack[e.To] && index[e.To] < low[name] {
+				low[name] = index[e.To]
+			}
+		}
+
+		if low[name] != index[name] {
+			return
+		}
+
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == name {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, n := range g.Nodes() {
+		if _, ok := index[n.Name]; !ok {
+			connect(n.Name)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
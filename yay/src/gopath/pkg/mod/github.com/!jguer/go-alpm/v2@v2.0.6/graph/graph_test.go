@@ -0,0 +1,176 @@
+// graph_test.go - Tests for dependency graphs.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Jguer/go-alpm/v2/alpmtest"
+)
+
+func testLocalDB() *alpmtest.DB {
+	return alpmtest.NewLocalDB(
+		alpmtest.NewPackage("yay", "11.0-1").WithDepends("pacman>5", "git").WithOptionalDepends("sudo: privilege elevation"),
+		alpmtest.NewPackage("pacman", "6.0.0-1").WithDepends("glibc", "sh", "libfoo>=2"),
+		alpmtest.NewPackage("git", "2.32-1").WithDepends("perl", "glibc"),
+		alpmtest.NewPackage("perl", "5.34-1").WithDepends("perl-module"),
+		alpmtest.NewPackage("perl-module", "1.0-1").WithDepends("perl"),
+		alpmtest.NewPackage("bash", "5.1-1").WithProvides("sh").WithDepends("glibc"),
+		alpmtest.NewPackage("glibc", "2.33-5"),
+		alpmtest.NewPackage("sudo", "1.9-1"),
+	)
+}
+
+func names(visits []Visit) []string {
+	names := []string{}
+	for _, v := range visits {
+		names = append(names, strings.Repeat(" ", v.Depth)+v.Node.Name)
+	}
+	return names
+}
+
+func TestFromDB(t *testing.T) {
+	g := FromDB(testLocalDB(), Options{})
+
+	if n := g.Node("libfoo>=2"); n == nil || !n.Missing() {
+		t.Errorf("missing dependency not in the graph")
+	}
+	if got := g.Dependencies("pacman"); len(got) != 3 || got[1].To != "bash" || got[1].Depend.String() != "sh" {
+		t.Errorf("unexpected pacman dependencies %v", got)
+	}
+
+	dependents := []string{}
+	for _, e := range g.Dependents("glibc") {
+		dependents = append(dependents, e.From)
+	}
+	if want := []string{"pacman", "git", "bash"}; !reflect.DeepEqual(dependents, want) {
+		t.Errorf("glibc dependents %v, want %v", dependents, want)
+	}
+	if g.Node("sudo") == nil || len(g.Dependents("sudo")) != 0 {
+		t.Errorf("optional dependency was added")
+	}
+}
+
+func TestTraverse(t *testing.T) {
+	g := FromDB(testLocalDB(), Options{Optional: true})
+
+	tests := []struct {
+		name string
+		root string
+		opts TraverseOptions
+		want []string
+	}{
+		{"forward", "yay", TraverseOptions{}, []string{
+			"yay", " pacman", "  glibc", "  bash", "  libfoo>=2", " git", "  perl", "   perl-module",
+		}},
+		{"depth", "yay", TraverseOptions{MaxDepth: 1, Optional: true}, []string{
+			"yay", " pacman", " git", " sudo",
+		}},
+		{"reverse", "glibc", TraverseOptions{Reverse: true}, []string{
+			"glibc", " pacman", "  yay", " git", " bash",
+		}},
+		{"reverse optional", "sudo", TraverseOptions{Reverse: true, Optional: true}, []string{
+			"sudo", " yay",
+		}},
+	}
+
+	for _, tt := range tests {
+		visits, err := g.Traverse(tt.root, tt.opts)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if got := names(visits); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := g.Traverse("nope", TraverseOptions{}); err == nil {
+		t.Errorf("traversing from an unknown node did not fail")
+	}
+}
+
+func TestCycles(t *testing.T) {
+	g := FromDB(testLocalDB(), Options{})
+
+	want := [][]string{{"perl", "perl-module"}}
+	if got := g.Cycles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFromTargets(t *testing.T) {
+	core := alpmtest.NewDB("core",
+		alpmtest.NewPackage("pacman", "6.0.1-1").WithDepends("glibc", "sh"),
+		alpmtest.NewPackage("glibc", "2.33-5"),
+	)
+	extra := alpmtest.NewDB("extra",
+		alpmtest.NewPackage("zsh", "5.8-1").WithProvides("sh").WithDepends("glibc"),
+	)
+	dbs := alpmtest.NewDBList(core, extra)
+
+	g, err := FromTargets(dbs, []string{"pacman"}, Options{})
+	if err != nil {
+		t.Fatalf("FromTargets failed: %s", err)
+	}
+
+	var nodes []string
+	for _, n := range g.Nodes() {
+		nodes = append(nodes, n.Package.DB().Name()+"/"+n.Name)
+	}
+	if want := []string{"core/glibc", "core/pacman", "extra/zsh"}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("got %v, want %v", nodes, want)
+	}
+
+	if _, err := FromTargets(dbs, []string{"pacman", "nope"}, Options{}); err == nil {
+		t.Errorf("unknown target did not fail")
+	}
+}
+
+func TestExport(t *testing.T) {
+	g, err := FromDB(testLocalDB(), Options{Optional: true}).Subgraph("yay", TraverseOptions{MaxDepth: 1, Optional: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := `digraph dependencies {
+	"git" [label="git\n2.32-1"];
+	"pacman" [label="pacman\n6.0.0-1"];
+	"sudo" [label="sudo\n1.9-1"];
+	"yay" [label="yay\n11.0-1"];
+	"yay" -> "pacman" [label="pacman>5"];
+	"yay" -> "git";
+	"yay" -> "sudo" [style=dashed];
+}
+`
+	if buf.String() != dot {
+		t.Errorf("got DOT\n%s\nwant\n%s", buf.String(), dot)
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Nodes []jsonNode
+		Edges []jsonEdge
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Nodes) != 4 || decoded.Nodes[3].DB != "local" || decoded.Edges[2] != (jsonEdge{"yay", "sudo", "sudo", "optdepends"}) {
+		t.Errorf("unexpected JSON %s", data)
+	}
+}
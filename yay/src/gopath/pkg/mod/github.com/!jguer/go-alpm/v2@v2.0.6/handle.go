@@ -13,7 +13,6 @@ package alpm
 
 // #include <alpm.h>
 // #include <stdio.h> //C.free
-// #include <fnmatch.h> //C.FNM_NOMATCH
 import "C"
 
 import (
@@ -32,7 +31,7 @@ type Handle struct {
 // helper functions for wrapping list_t getters and setters
 func (h *Handle) optionGetList(f func(*C.alpm_handle_t) *C.alpm_list_t) (StringList, error) {
 	alpmList := f(h.ptr)
-	goList := StringList{(*list)(unsafe.Pointer(alpmList))}
+	goList := convertStringList((*list)(unsafe.Pointer(alpmList)))
 
 	if alpmList == nil {
 		return goList, h.LastError()
@@ -76,16 +75,14 @@ func (h *Handle) optionRemoveList(dir string, f func(*C.alpm_handle_t, *C.char)
 	return ok == 1, nil
 }
 
+// optionMatchList matches a path against a list of globs. libalpm returns 0
+// on a match, 1 when a negated "!glob" matches and -1 when nothing matches,
+// none of which is an error.
 func (h *Handle) optionMatchList(dir string, f func(*C.alpm_handle_t, *C.char) C.int) (bool, error) {
 	cDir := C.CString(dir)
-	ok := f(h.ptr, cDir)
 	defer C.free(unsafe.Pointer(cDir))
-	if ok == 0 {
-		return true, nil
-	} else if ok == C.FNM_NOMATCH {
-		return false, h.LastError()
-	}
-	return false, nil
+
+	return f(h.ptr, cDir) == 0, nil
 }
 
 // helper functions for *char based getters and setters
@@ -110,6 +107,23 @@ func (h *Handle) optionSetStr(str string, f func(*C.alpm_handle_t, *C.char) C.in
 	return nil
 }
 
+func (h *Handle) optionGetSigLevel(f func(*C.alpm_handle_t) C.int) (SigLevel, error) {
+	sigLevel := f(h.ptr)
+	if sigLevel < 0 {
+		return 0, h.LastError()
+	}
+
+	return SigLevel(sigLevel), nil
+}
+
+func (h *Handle) optionSetSigLevel(siglevel SigLevel, f func(*C.alpm_handle_t, C.int) C.int) error {
+	if f(h.ptr, C.int(siglevel)) < 0 {
+		return h.LastError()
+	}
+
+	return nil
+}
+
 //
 // end of helpers
 //
@@ -338,9 +352,35 @@ func (h *Handle) RemoveIgnoreGroup(dir string) (bool, error) {
 	})
 }
 
+// OverwriteFiles returns the globs of files that may be overwritten by a
+// transaction.
+func (h *Handle) OverwriteFiles() (StringList, error) {
+	return h.optionGetList(func(handle *C.alpm_handle_t) *C.alpm_list_t {
+		return C.alpm_option_get_overwrite_files(handle)
+	})
+}
+
+func (h *Handle) AddOverwriteFile(glob string) error {
+	return h.optionAddList(glob, func(handle *C.alpm_handle_t, str *C.char) C.int {
+		return C.alpm_option_add_overwrite_file(handle, str)
+	})
+}
+
+func (h *Handle) SetOverwriteFiles(globs []string) error {
+	return h.optionSetList(globs, func(handle *C.alpm_handle_t, l *C.alpm_list_t) C.int {
+		return C.alpm_option_set_overwrite_files(handle, l)
+	})
+}
+
+func (h *Handle) RemoveOverwriteFile(glob string) (bool, error) {
+	return h.optionRemoveList(glob, func(handle *C.alpm_handle_t, str *C.char) C.int {
+		return C.alpm_option_remove_overwrite_file(handle, str)
+	})
+}
+
 /*func (h *Handle) optionGetList(f func(*C.alpm_handle_t) *C.alpm_list_t) (StringList, error){
 	alpmList := f(h.ptr)
-	goList := StringList{(*list)(unsafe.Pointer(alpmList))}
+	goList := convertStringList((*list)(unsafe.Pointer(alpmList)))
 
 	if alpmList == nil {
 		return goList, h.LastError()
@@ -351,7 +391,7 @@ func (h *Handle) RemoveIgnoreGroup(dir string) (bool, error) {
 // use alpm_depend_t
 func (h *Handle) AssumeInstalled() (DependList, error) {
 	alpmList := C.alpm_option_get_assumeinstalled(h.ptr)
-	depList := DependList{(*list)(unsafe.Pointer(alpmList))}
+	depList := convertDependList((*list)(unsafe.Pointer(alpmList)))
 
 	if alpmList == nil {
 		return depList, h.LastError()
@@ -470,12 +510,15 @@ func (h *Handle) SetCheckSpace(value bool) error {
 	return nil
 }
 
+// DBExt returns the extension of the sync database files, ".db" by default.
 func (h *Handle) DBExt() (string, error) {
 	return h.optionGetStr(func(handle *C.alpm_handle_t) *C.char {
 		return C.alpm_option_get_dbext(handle)
 	})
 }
 
+// SetDBExt sets the extension of the sync database files, for example
+// ".files" to use the file list databases.
 func (h *Handle) SetDBExt(str string) error {
 	return h.optionSetStr(str, func(handle *C.alpm_handle_t, cStr *C.char) C.int {
 		return C.alpm_option_set_dbext(handle, cStr)
@@ -483,57 +526,39 @@ func (h *Handle) SetDBExt(str string) error {
 }
 
 func (h *Handle) GetDefaultSigLevel() (SigLevel, error) {
-	sigLevel := C.alpm_option_get_default_siglevel(h.ptr)
-
-	if sigLevel < 0 {
-		return SigLevel(sigLevel), h.LastError()
-	}
-	return SigLevel(sigLevel), nil
+	return h.optionGetSigLevel(func(handle *C.alpm_handle_t) C.int {
+		return C.alpm_option_get_default_siglevel(handle)
+	})
 }
 
 func (h *Handle) SetDefaultSigLevel(siglevel SigLevel) error {
-	ok := C.alpm_option_set_default_siglevel(h.ptr, C.int(siglevel))
-
-	if ok < 0 {
-		return h.LastError()
-	}
-	return nil
+	return h.optionSetSigLevel(siglevel, func(handle *C.alpm_handle_t, level C.int) C.int {
+		return C.alpm_option_set_default_siglevel(handle, level)
+	})
 }
 
 func (h *Handle) GetLocalFileSigLevel() (SigLevel, error) {
-	sigLevel := C.alpm_option_get_local_file_siglevel(h.ptr)
-
-	if sigLevel < 0 {
-		return SigLevel(sigLevel), h.LastError()
-	}
-	return SigLevel(sigLevel), nil
+	return h.optionGetSigLevel(func(handle *C.alpm_handle_t) C.int {
+		return C.alpm_option_get_local_file_siglevel(handle)
+	})
 }
 
 func (h *Handle) SetLocalFileSigLevel(siglevel SigLevel) error {
-	ok := C.alpm_option_set_local_file_siglevel(h.ptr, C.int(siglevel))
-
-	if ok < 0 {
-		return h.LastError()
-	}
-	return nil
+	return h.optionSetSigLevel(siglevel, func(handle *C.alpm_handle_t, level C.int) C.int {
+		return C.alpm_option_set_local_file_siglevel(handle, level)
+	})
 }
 
 func (h *Handle) GetRemoteFileSigLevel() (SigLevel, error) {
-	sigLevel := C.alpm_option_get_remote_file_siglevel(h.ptr)
-
-	if sigLevel < 0 {
-		return SigLevel(sigLevel), h.LastError()
-	}
-	return SigLevel(sigLevel), nil
+	return h.optionGetSigLevel(func(handle *C.alpm_handle_t) C.int {
+		return C.alpm_option_get_remote_file_siglevel(handle)
+	})
 }
 
 func (h *Handle) SetRemoteFileSigLevel(siglevel SigLevel) error {
-	ok := C.alpm_option_set_remote_file_siglevel(h.ptr, C.int(siglevel))
-
-	if ok < 0 {
-		return h.LastError()
-	}
-	return nil
+	return h.optionSetSigLevel(siglevel, func(handle *C.alpm_handle_t, level C.int) C.int {
+		return C.alpm_option_set_remote_file_siglevel(handle, level)
+	})
 }
 
 func (h *Handle) GetArchitectures() (StringList, error) {
@@ -559,3 +584,37 @@ func (h *Handle) RemoveArchitecture(str string) (bool, error) {
 		return C.alpm_option_remove_architecture(handle, cStr)
 	})
 }
+
+// ParallelDownloads returns the number of files downloaded at the same time.
+func (h *Handle) ParallelDownloads() (uint, error) {
+	n := C.alpm_option_get_parallel_downloads(h.ptr)
+	if n < 0 {
+		return 0, h.LastError()
+	}
+	return uint(n), nil
+}
+
+// SetParallelDownloads sets the number of files downloaded at the same time,
+// which must be at least 1.
+func (h *Handle) SetParallelDownloads(n uint) error {
+	ok := C.alpm_option_set_parallel_downloads(h.ptr, C.uint(n))
+	if ok < 0 {
+		return h.LastError()
+	}
+	return nil
+}
+
+// SetDisableDLTimeout disables the low speed timeout of the downloader.
+// libalpm provides no getter for this option.
+func (h *Handle) SetDisableDLTimeout(disable bool) error {
+	var cValue C.ushort
+	if disable {
+		cValue = 1
+	}
+
+	ok := C.alpm_option_set_disable_dl_timeout(h.ptr, cValue)
+	if ok < 0 {
+		return h.LastError()
+	}
+	return nil
+}
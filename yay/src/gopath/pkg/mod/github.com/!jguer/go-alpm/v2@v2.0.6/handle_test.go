@@ -0,0 +1,93 @@
+//go:build cgo
+// +build cgo
+
+// handle_test.go - Tests for handle.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchNoUpgrade(t *testing.T) {
+	h := initTestRoot(t)
+	if err := h.SetNoUpgrades([]string{"etc/*.conf", "!etc/pacman.conf", "usr/share/doc/*"}); err != nil {
+		t.Fatalf("SetNoUpgrades failed: %s", err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"etc/makepkg.conf", true},
+		{"etc/pacman.conf", false},
+		{"etc/pacman.d/mirrorlist", false},
+		{"usr/share/doc/pacman/README", true},
+		{"usr/bin/pacman", false},
+	}
+
+	for _, tt := range tests {
+		got, err := h.MatchNoUpgrade(tt.path)
+		if err != nil {
+			t.Errorf("%s: %s", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("MatchNoUpgrade(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOverwriteFiles(t *testing.T) {
+	h := initTestRoot(t)
+
+	if err := h.SetOverwriteFiles([]string{"usr/lib/*"}); err != nil {
+		t.Fatalf("SetOverwriteFiles failed: %s", err)
+	}
+	if err := h.AddOverwriteFile("etc/*"); err != nil {
+		t.Fatalf("AddOverwriteFile failed: %s", err)
+	}
+	if removed, err := h.RemoveOverwriteFile("usr/lib/*"); err != nil || !removed {
+		t.Errorf("RemoveOverwriteFile failed: %t, %v", removed, err)
+	}
+
+	globs, err := h.OverwriteFiles()
+	if err != nil {
+		t.Fatalf("OverwriteFiles failed: %s", err)
+	}
+	if got := globs.Slice(); !reflect.DeepEqual(got, []string{"etc/*"}) {
+		t.Errorf("got %v, want [etc/*]", got)
+	}
+}
+
+func TestParallelDownloads(t *testing.T) {
+	h := initTestRoot(t)
+
+	if err := h.SetParallelDownloads(5); err != nil {
+		t.Fatalf("SetParallelDownloads failed: %s", err)
+	}
+	if n, err := h.ParallelDownloads(); err != nil || n != 5 {
+		t.Errorf("got %d, %v, want 5", n, err)
+	}
+	if err := h.SetParallelDownloads(0); err == nil {
+		t.Errorf("SetParallelDownloads(0) should fail")
+	}
+	if err := h.SetDisableDLTimeout(true); err != nil {
+		t.Errorf("SetDisableDLTimeout failed: %s", err)
+	}
+}
+
+func TestSigLevelOptions(t *testing.T) {
+	h := initTestRoot(t)
+
+	if err := h.SetDefaultSigLevel(SigPackage | SigDatabaseOptional); err != nil {
+		t.Fatalf("SetDefaultSigLevel failed: %s", err)
+	}
+	if level, err := h.GetDefaultSigLevel(); err != nil || level != SigPackage|SigDatabaseOptional {
+		t.Errorf("got %d, %v", level, err)
+	}
+}
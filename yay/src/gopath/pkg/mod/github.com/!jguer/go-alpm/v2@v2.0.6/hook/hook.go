@@ -0,0 +1,328 @@
+// hook.go - Parse alpm hook files.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+// Package hook parses alpm hook files, as described in alpm-hooks(5), and
+// finds the hooks a transaction would run.
+package hook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Operation is a set of transaction operations triggering a hook.
+type Operation int
+
+const (
+	OpInstall Operation = 1 << iota
+	OpUpgrade
+	OpRemove
+)
+
+func (op Operation) String() string {
+	s := ""
+	for _, o := range []struct {
+		flag Operation
+		name string
+	}{{OpInstall, "Install"}, {OpUpgrade, "Upgrade"}, {OpRemove, "Remove"}} {
+		if op&o.flag == 0 {
+			continue
+		}
+		if s != "" {
+			s += ","
+		}
+		s += o.name
+	}
+	return s
+}
+
+// Type is what the targets of a trigger are matched against.
+type Type int
+
+const (
+	TypePath    Type = iota + 1 // Targets match file paths.
+	TypePackage                 // Targets match package names.
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypePath:
+		return "Path"
+	case TypePackage:
+		return "Package"
+	}
+	return ""
+}
+
+// When is the moment a hook runs.
+type When int
+
+const (
+	PreTransaction When = iota + 1
+	PostTransaction
+)
+
+func (w When) String() string {
+	switch w {
+	case PreTransaction:
+		return "PreTransaction"
+	case PostTransaction:
+		return "PostTransaction"
+	}
+	return ""
+}
+
+// Trigger is a [Trigger] section. Targets are fnmatch(3) patterns, a
+// leading ! negates a pattern and the last matching pattern wins.
+type Trigger struct {
+	Operations Operation
+	Type       Type
+	Targets    []string
+
+	// patterns are the compiled Targets, set by Parse.
+	patterns []pattern
+}
+
+// Hook is a parsed hook file.
+type Hook struct {
+	// Name is the file name without the .hook suffix.
+	Name string
+	Path string
+
+	Triggers     []Trigger
+	Description  string
+	When         When
+	Exec         string
+	Depends      []string
+	AbortOnFail  bool
+	NeedsTargets bool
+}
+
+// Parse reads a hook file. name is used in errors and as the hook name.
+// Like libalpm, unknown options are an error and repeated single-valued
+// options override previous ones.
+func Parse(r io.Reader, name string) (*Hook, error) {
+	hook := &Hook{Name: strings.TrimSuffix(name, ".hook")}
+	section := ""
+	scanner := bufio.NewScanner(r)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			switch section {
+			case "Trigger":
+				hook.Triggers = append(hook.Triggers, Trigger{})
+			case "Action":
+			default:
+				return nil, fmt.Errorf("%s:%d: invalid section %s", name, n, section)
+			}
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.Index(line, "="); i >= 0 {
+			key = strings.TrimSpace(line[:i])
+			value = strings.TrimSpace(line[i+1:])
+		}
+
+		var err error
+		switch section {
+		case "Trigger":
+			err = parseTriggerOption(&hook.Triggers[len(hook.Triggers)-1], key, value)
+		case "Action":
+			err = parseActionOption(hook, key, value)
+		default:
+			err = fmt.Errorf("option %s outside of a section", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := hook.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return hook, nil
+}
+
+func parseTriggerOption(t *Trigger, key, value string) error {
+	switch key {
+	case "Operation":
+		switch value {
+		case "Install":
+			t.Operations |= OpInstall
+		case "Upgrade":
+			t.Operations |= OpUpgrade
+		case "Remove":
+			t.Operations |= OpRemove
+		default:
+			return fmt.Errorf("invalid value for Operation: %s", value)
+		}
+	case "Type":
+		switch value {
+		// File is the deprecated name of Path
+		case "Path", "File":
+			t.Type = TypePath
+		case "Package":
+			t.Type = TypePackage
+		default:
+			return fmt.Errorf("invalid value for Type: %s", value)
+		}
+	case "Target":
+		if value == "" {
+			return fmt.Errorf("empty Target")
+		}
+		t.Targets = append(t.Targets, value)
+	default:
+		return fmt.Errorf("invalid option %s", key)
+	}
+	return nil
+}
+
+func parseActionOption(hook *Hook, key, value string) error {
+	switch key {
+	case "Description":
+		hook.Description = value
+	case "When":
+		switch value {
+		case "PreTransaction":
+			hook.When = PreTransaction
+		case "PostTransaction":
+			hook.When = PostTransaction
+		default:
+			return fmt.Errorf("invalid value for When: %s", value)
+		}
+	case "Exec":
+		hook.Exec = value
+	case "Depends":
+		hook.Depends = append(hook.Depends, value)
+	case "AbortOnFail":
+		hook.AbortOnFail = true
+	case "NeedsTargets":
+		hook.NeedsTargets = true
+	default:
+		return fmt.Errorf("invalid option %s", key)
+	}
+	return nil
+}
+
+func (hook *Hook) validate() error {
+	if len(hook.Triggers) == 0 {
+		return fmt.Errorf("missing trigger")
+	}
+	for i := range hook.Triggers {
+		t := &hook.Triggers[i]
+		switch {
+		case t.Operations == 0:
+			return fmt.Errorf("missing trigger Operation")
+		case t.Type == 0:
+			return fmt.Errorf("missing trigger Type")
+		case len(t.Targets) == 0:
+			return fmt.Errorf("missing trigger Target")
+		}
+
+		patterns, err := compilePatterns(t.Targets)
+		if err != nil {
+			return err
+		}
+		t.patterns = patterns
+	}
+
+	if hook.Exec == "" {
+		return fmt.Errorf("missing Exec")
+	}
+	if hook.When == 0 {
+		return fmt.Errorf("missing When")
+	}
+	// libalpm only honours AbortOnFail for PreTransaction hooks
+	if hook.When != PreTransaction {
+		hook.AbortOnFail = false
+	}
+
+	return nil
+}
+
+// ParseFile reads the hook file at path.
+func ParseFile(path string) (*Hook, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hook, err := Parse(f, filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	hook.Path = path
+
+	return hook, nil
+}
+
+// LoadDirs reads the .hook files of dirs, such as Handle.HookDirs. A hook
+// overrides hooks of the same name in earlier directories, and a hook
+// symlinked to /dev/null disables them. The hooks are sorted by name, the
+// order libalpm runs them in. Missing directories are skipped.
+func LoadDirs(dirs ...string) ([]*Hook, error) {
+	byName := map[string]*Hook{}
+
+	for _, dir := range dirs {
+		infos, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, info := range infos {
+			if info.IsDir() || !strings.HasSuffix(info.Name(), ".hook") {
+				continue
+			}
+
+			path := filepath.Join(dir, info.Name())
+			name := strings.TrimSuffix(info.Name(), ".hook")
+
+			if info.Mode()&os.ModeSymlink != 0 {
+				if target, err := os.Readlink(path); err == nil && target == "/dev/null" {
+					delete(byName, name)
+					continue
+				}
+			}
+
+			hook, err := ParseFile(path)
+			if err != nil {
+				return nil, err
+			}
+			byName[name] = hook
+		}
+	}
+
+	hooks := make([]*Hook, 0, len(byName))
+	for _, hook := range byName {
+		hooks = append(hooks, hook)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].Name < hooks[j].Name
+	})
+
+	return hooks, nil
+}
@@ -0,0 +1,216 @@
+// hook_test.go - Tests for hook parsing and matching.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package hook
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/alpmtest"
+)
+
+const systemdHook = `# comment
+[Trigger]
+Type = Path
+Operation = Install
+Operation = Upgrade
+Operation = Remove
+Target = usr/lib/systemd/system/*
+
+[Action]
+Description = Reloading system manager configuration...
+When = PostTransaction
+Exec = /usr/share/libalpm/scripts/systemd-hook daemon-reload
+Depends = systemd
+NeedsTargets
+AbortOnFail
+`
+
+func TestParse(t *testing.T) {
+	hook, err := Parse(strings.NewReader(systemdHook), "30-systemd-daemon-reload.hook")
+	if err != nil {
+		t.Fatalf("Parse failed: %s", err)
+	}
+
+	want := &Hook{
+		Name: "30-systemd-daemon-reload",
+		Triggers: []Trigger{{
+			Operations: OpInstall | OpUpgrade | OpRemove,
+			Type:       TypePath,
+			Targets:    []string{"usr/lib/systemd/system/*"},
+		}},
+		Description:  "Reloading system manager configuration...",
+		When:         PostTransaction,
+		Exec:         "/usr/share/libalpm/scripts/systemd-hook daemon-reload",
+		Depends:      []string{"systemd"},
+		NeedsTargets: true,
+	}
+	if len(hook.Triggers[0].patterns) != 1 {
+		t.Errorf("Target was not compiled")
+	}
+	hook.Triggers[0].patterns = nil
+	if !reflect.DeepEqual(hook, want) {
+		t.Errorf("got %+v, want %+v", hook, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		hook string
+		err  string
+	}{
+		{"no trigger", "[Action]\nWhen = PreTransaction\nExec = /bin/true\n", "missing trigger"},
+		{"no target", "[Trigger]\nType = Package\nOperation = Install\n[Action]\nWhen = PreTransaction\nExec = /bin/true\n", "missing trigger Target"},
+		{"no exec", "[Trigger]\nType = Package\nOperation = Install\nTarget = *\n[Action]\nWhen = PreTransaction\n", "missing Exec"},
+		{"bad when", "[Action]\nWhen = Later\n", "invalid value for When"},
+		{"bad type", "[Trigger]\nType = Dir\n", "invalid value for Type"},
+		{"bad section", "[Hook]\n", "invalid section"},
+		{"no section", "Exec = /bin/true\n", "outside of a section"},
+		{"bad trigger option", "[Trigger]\nTargets = *\n", "invalid option Targets"},
+		{"bad target", "[Trigger]\nType = Package\nOperation = Install\nTarget = lib[z-a]\n[Action]\nWhen = PreTransaction\nExec = /bin/true\n", "invalid Target lib[z-a]"},
+		{"bad action option", "[Action]\nExecute = /bin/true\n", "invalid option Execute"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.hook), "test.hook")
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func writeHook(t *testing.T, dir, name, target string) {
+	t.Helper()
+	hook := "[Trigger]\nType = Package\nOperation = Install\nTarget = " + target + "\n[Action]\nWhen = PreTransaction\nExec = /bin/true\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(hook), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDirs(t *testing.T) {
+	system, user := t.TempDir(), t.TempDir()
+	writeHook(t, system, "b.hook", "system")
+	writeHook(t, system, "a.hook", "system")
+	writeHook(t, system, "c.hook", "system")
+	writeHook(t, user, "a.hook", "user")
+	writeHook(t, user, "README", "user")
+	if err := os.Symlink("/dev/null", filepath.Join(user, "c.hook")); err != nil {
+		t.Fatal(err)
+	}
+
+	hooks, err := LoadDirs(system, user, filepath.Join(user, "missing"))
+	if err != nil {
+		t.Fatalf("LoadDirs failed: %s", err)
+	}
+
+	if len(hooks) != 2 || hooks[0].Name != "a" || hooks[1].Name != "b" {
+		t.Fatalf("unexpected hooks %v", hooks)
+	}
+	if hooks[0].Triggers[0].Targets[0] != "user" || hooks[0].Path != filepath.Join(user, "a.hook") {
+		t.Errorf("user hook did not override the system one")
+	}
+}
+
+func TestFnmatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"usr/lib/modules/*/vmlinuz", "usr/lib/modules/5.13.1-arch1-1/vmlinuz", true},
+		{"usr/share/*", "usr/share/a/b/c", true},
+		{"linux?", "linux5", true},
+		{"linux?", "linux", false},
+		{"linux[0-9]", "linux5", true},
+		{"linux[!0-9]", "linux5", false},
+		{"a.b", "axb", false},
+		{"lib[", "lib[", true},
+		{`\*`, "*", true},
+	}
+
+	for _, tt := range tests {
+		re, err := fnmatch(tt.pattern)
+		if err != nil {
+			t.Errorf("fnmatch(%q) failed: %s", tt.pattern, err)
+			continue
+		}
+		if got := re.MatchString(tt.s); got != tt.want {
+			t.Errorf("fnmatch(%q) matches %q: %v", tt.pattern, tt.s, got)
+		}
+	}
+
+	patterns, err := compilePatterns([]string{"linux*", "!linux-headers", "!*-docs", "linux-lts-docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s, want := range map[string]bool{"linux": true, "linux-headers": false, "linux-docs": false, "linux-lts-docs": true, "zsh": false} {
+		if got := matchPatterns(patterns, s); got != want {
+			t.Errorf("matchPatterns(%s) = %v", s, got)
+		}
+	}
+}
+
+func files(names ...string) []alpm.File {
+	files := make([]alpm.File, len(names))
+	for i, name := range names {
+		files[i] = alpm.File{Name: name}
+	}
+	return files
+}
+
+func TestMatches(t *testing.T) {
+	local := alpmtest.NewLocalDB(
+		alpmtest.NewPackage("systemd", "248-1").WithFiles(files("usr/lib/systemd/system/a.service", "usr/lib/systemd/system/old.service")...),
+		alpmtest.NewPackage("foo", "1.0-1").WithFiles(files("usr/lib/systemd/system/foo.service")...),
+	)
+	tx := &Transaction{
+		Add: []alpm.IPackage{
+			alpmtest.NewPackage("systemd", "249-1").WithFiles(files("usr/lib/systemd/system/a.service", "usr/lib/systemd/system/new.service")...),
+			// foo.service moves from foo to foo-ng
+			alpmtest.NewPackage("foo-ng", "2.0-1").WithFiles(files("usr/lib/systemd/system/foo.service", "usr/bin/foo")...),
+		},
+		Remove: []alpm.IPackage{local.Pkg("foo")},
+		Local:  local,
+	}
+
+	parse := func(name, hook string) *Hook {
+		h, err := Parse(strings.NewReader(hook), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	action := "[Action]\nWhen = PostTransaction\nExec = /bin/true\n"
+	hooks := []*Hook{
+		parse("installed", "[Trigger]\nType = Path\nOperation = Install\nTarget = usr/lib/systemd/system/*\n"+action),
+		parse("upgraded", "[Trigger]\nType = Path\nOperation = Upgrade\nTarget = usr/lib/systemd/system/*\n"+action),
+		parse("removed", "[Trigger]\nType = Path\nOperation = Remove\nTarget = usr/lib/systemd/system/*\n"+action),
+		parse("pkg-install", "[Trigger]\nType = Package\nOperation = Install\nTarget = *\n"+action),
+		parse("pkg-upgrade", "[Trigger]\nType = Package\nOperation = Upgrade\nOperation = Remove\nTarget = *\nTarget = !foo\n"+action),
+		parse("none", "[Trigger]\nType = Path\nOperation = Install\nTarget = etc/*\n"+action),
+	}
+
+	got := map[string][]string{}
+	for _, m := range Matches(hooks, tx) {
+		got[m.Hook.Name] = m.Targets
+	}
+	want := map[string][]string{
+		"installed":   {"usr/lib/systemd/system/new.service"},
+		"upgraded":    {"usr/lib/systemd/system/a.service", "usr/lib/systemd/system/foo.service"},
+		"removed":     {"usr/lib/systemd/system/old.service"},
+		"pkg-install": {"foo-ng"},
+		"pkg-upgrade": {"systemd"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
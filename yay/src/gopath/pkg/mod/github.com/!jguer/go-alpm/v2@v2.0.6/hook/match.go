@@ -0,0 +1,258 @@
+// match.go - Find the hooks triggered by a transaction.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package hook
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+)
+
+// Transaction is a planned transaction, such as the result of
+// Handle.TransGetAdd and Handle.TransGetRemove. Path triggers need the file
+// lists of the packages, which sync packages only have with the .files
+// databases.
+type Transaction struct {
+	Add    []alpm.IPackage
+	Remove []alpm.IPackage
+	// Local is the local database, used to tell installs from upgrades.
+	Local alpm.IDB
+}
+
+// Match is a hook triggered by a transaction, with the matched package
+// names or paths, sorted and without duplicates.
+type Match struct {
+	Hook    *Hook
+	Targets []string
+}
+
+// Matches returns the hooks triggered by tx, in the order of hooks. The
+// targets of triggers not built by Parse are compiled on each call, and the
+// trigger never matches if one does not compile.
+func Matches(hooks []*Hook, tx *Transaction) []Match {
+	pkgs := tx.packageTargets()
+	var files map[Operation][]string
+
+	matches := []Match{}
+	for _, hook := range hooks {
+		targets := map[string]bool{}
+		for _, t := range hook.Triggers {
+			patterns := t.patterns
+			if patterns == nil {
+				var err error
+				if patterns, err = compilePatterns(t.Targets); err != nil {
+					continue
+				}
+			}
+
+			candidates := pkgs
+			if t.Type == TypePath {
+				if files == nil {
+					files = tx.fileTargets()
+				}
+				candidates = files
+			}
+
+			for _, op := range []Operation{OpInstall, OpUpgrade, OpRemove} {
+				if t.Operations&op == 0 {
+					continue
+				}
+				for _, c := range candidates[op] {
+					if matchPatterns(patterns, c) {
+						targets[c] = true
+					}
+				}
+			}
+		}
+
+		if len(targets) == 0 {
+			continue
+		}
+
+		m := Match{Hook: hook}
+		for target := range targets {
+			m.Targets = append(m.Targets, target)
+		}
+		sort.Strings(m.Targets)
+		matches = append(matches, m)
+	}
+
+	return matches
+}
+
+// packageTargets returns the package names of tx by operation.
+func (tx *Transaction) packageTargets() map[Operation][]string {
+	targets := map[Operation][]string{}
+	for _, pkg := range tx.Add {
+		op := OpInstall
+		if tx.Local != nil && tx.Local.Pkg(pkg.Name()) != nil {
+			op = OpUpgrade
+		}
+		targets[op] = append(targets[op], pkg.Name())
+	}
+	for _, pkg := range tx.Remove {
+		targets[OpRemove] = append(targets[OpRemove], pkg.Name())
+	}
+	return targets
+}
+
+// fileTargets returns the paths of tx by operation. Like libalpm, a path
+// kept by an upgraded package or moved from a removed package to an added
+// one is upgraded rather than installed and removed.
+func (tx *Transaction) fileTargets() map[Operation][]string {
+	install := map[string]bool{}
+	upgrade := map[string]bool{}
+	remove := map[string]bool{}
+
+	for _, pkg := range tx.Add {
+		old := map[string]bool{}
+		if tx.Local != nil {
+			if local := tx.Local.Pkg(pkg.Name()); local != nil {
+				for _, file := range local.Files() {
+					old[file.Name] = true
+				}
+			}
+		}
+
+		for _, file := range pkg.Files() {
+			if old[file.Name] {
+				upgrade[file.Name] = true
+				delete(old, file.Name)
+			} else {
+				install[file.Name] = true
+			}
+		}
+		for name := range old {
+			remove[name] = true
+		}
+	}
+	for _, pkg := range tx.Remove {
+		for _, file := range pkg.Files() {
+			remove[file.Name] = true
+		}
+	}
+
+	for name := range install {
+		if remove[name] {
+			delete(install, name)
+			delete(remove, name)
+			upgrade[name] = true
+		}
+	}
+
+	return map[Operation][]string{
+		OpInstall: sortedKeys(install),
+		OpUpgrade: sortedKeys(upgrade),
+		OpRemove:  sortedKeys(remove),
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pattern is a compiled trigger target.
+type pattern struct {
+	re       *regexp.Regexp
+	inverted bool
+}
+
+// compilePatterns compiles trigger targets. A leading ! inverts a target and
+// a leading backslash escapes a ! or backslash.
+func compilePatterns(targets []string) ([]pattern, error) {
+	patterns := make([]pattern, len(targets))
+	for i, target := range targets {
+		p := target
+		inverted := strings.HasPrefix(p, "!")
+		if inverted || strings.HasPrefix(p, "\\") {
+			p = p[1:]
+		}
+
+		re, err := fnmatch(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Target %s: %w", target, err)
+		}
+		patterns[i] = pattern{re, inverted}
+	}
+	return patterns, nil
+}
+
+// matchPatterns matches s against patterns the way libalpm does: the last
+// matching pattern decides, and it is a miss if it is inverted.
+func matchPatterns(patterns []pattern, s string) bool {
+	for i := len(patterns) - 1; i >= 0; i-- {
+		if patterns[i].re.MatchString(s) {
+			return !patterns[i].inverted
+		}
+	}
+	return false
+}
+
+// fnmatch compiles a fnmatch(3) pattern without flags, where * also matches
+// slashes.
+func fnmatch(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := classEnd(pattern, i)
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
+
+// classEnd returns the index of the bracket closing the class opened at
+// start, or -1 if it is not closed.
+func classEnd(pattern string, start int) int {
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '!' {
+		i++
+	}
+	// a leading ] is part of the class
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		if pattern[i] == ']' {
+			return i
+		}
+	}
+	return -1
+}
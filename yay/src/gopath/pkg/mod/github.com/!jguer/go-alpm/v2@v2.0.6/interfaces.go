@@ -10,6 +10,8 @@ type IPackage interface {
 	Base() string
 	Base64Signature() string
 	Validation() Validation
+	// CheckPGPSignature checks the package PGP signature.
+	CheckPGPSignature() (SigList, error)
 	// Architecture returns the package target Architecture.
 	Architecture() string
 	// Backup returns a list of package backups.
@@ -108,6 +110,8 @@ type IDB interface {
 	// PkgCache returns the list of packages of the database
 	PkgCache() IPackageList
 	Search([]string) IPackageList
+	// CheckPGPSignature checks the database PGP signature.
+	CheckPGPSignature() (SigList, error)
 }
 
 // IDBList interfaces alpm.DBList
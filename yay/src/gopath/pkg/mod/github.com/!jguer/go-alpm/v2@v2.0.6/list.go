@@ -0,0 +1,95 @@
+// list.go - Lists of package values.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+// The lists below are copied from libalpm when read from a package, so they
+// do not need cgo and can be built from Go values with a conversion, such
+// as DependList(deps), to implement IPackage outside of this package.
+
+// StringList is a list of strings, such as the groups of a package.
+type StringList []string
+
+// ForEach executes an action on each string of the StringList.
+func (l StringList) ForEach(f func(string) error) error {
+	for _, s := range l {
+		if err := f(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Slice converts the StringList to a string slice.
+func (l StringList) Slice() []string {
+	return append([]string{}, l...)
+}
+
+// Len returns the number of strings in the StringList.
+func (l StringList) Len() int {
+	return len(l)
+}
+
+// Empty reports whether the StringList has no strings.
+func (l StringList) Empty() bool {
+	return len(l) == 0
+}
+
+// DependList is a list of dependencies, such as the depends of a package.
+type DependList []Depend
+
+// ForEach executes an action on each dependency of the DependList.
+func (l DependList) ForEach(f func(Depend) error) error {
+	for _, dep := range l {
+		if err := f(dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Slice converts the DependList to a Depend slice.
+func (l DependList) Slice() []Depend {
+	return append([]Depend{}, l...)
+}
+
+// Len returns the number of dependencies in the DependList.
+func (l DependList) Len() int {
+	return len(l)
+}
+
+// Empty reports whether the DependList has no dependencies.
+func (l DependList) Empty() bool {
+	return len(l) == 0
+}
+
+// BackupList is the list of backup files of a package.
+type BackupList []BackupFile
+
+// ForEach executes an action on each file of the BackupList.
+func (l BackupList) ForEach(f func(BackupFile) error) error {
+	for _, file := range l {
+		if err := f(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Slice converts the BackupList to a BackupFile slice.
+func (l BackupList) Slice() []BackupFile {
+	return append([]BackupFile{}, l...)
+}
+
+// Len returns the number of files in the BackupList.
+func (l BackupList) Len() int {
+	return len(l)
+}
+
+// Empty reports whether the BackupList has no files.
+func (l BackupList) Empty() bool {
+	return len(l) == 0
+}
@@ -0,0 +1,203 @@
+// mtree.go - Read the mtree file lists of installed packages.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MtreeEntry is a file described by the mtree of a package.
+type MtreeEntry struct {
+	// Path is relative to the root, without the leading "./".
+	Path string
+	// Type is "file", "dir" or "link".
+	Type string
+	// Mode holds the permission bits, with setuid, setgid and sticky.
+	Mode    uint32
+	UID     int
+	GID     int
+	Size    int64
+	ModTime time.Time
+	Link    string
+	SHA256  string
+	MD5     string
+}
+
+// ReadMtree reads an mtree file, gzip compressed as in the local database
+// or not.
+func ReadMtree(path string) ([]MtreeEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if magic, err := r.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		return ParseMtree(gz)
+	}
+
+	return ParseMtree(r)
+}
+
+// ParseMtree parses an mtree file in the format written by bsdtar. Package
+// metadata files such as .PKGINFO are left out.
+func ParseMtree(r io.Reader) ([]MtreeEntry, error) {
+	entries := []MtreeEntry{}
+	defaults := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "/set":
+			for _, field := range fields[1:] {
+				key, value := splitKeyword(field)
+				defaults[key] = value
+			}
+			continue
+		case "/unset":
+			for _, key := range fields[1:] {
+				delete(defaults, key)
+			}
+			continue
+		}
+
+		path, err := unescapeMtree(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("mtree line %d: %w", n, err)
+		}
+		path = strings.TrimPrefix(path, "./")
+		if path == "." || (strings.HasPrefix(path, ".") && !strings.Contains(path, "/")) {
+			continue
+		}
+
+		keywords := map[string]string{}
+		for k, v := range defaults {
+			keywords[k] = v
+		}
+		for _, field := range fields[1:] {
+			key, value := splitKeyword(field)
+			keywords[key] = value
+		}
+
+		entry, err := newMtreeEntry(path, keywords)
+		if err != nil {
+			return nil, fmt.Errorf("mtree line %d: %w", n, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+func splitKeyword(field string) (string, string) {
+	if i := strings.Index(field, "="); i >= 0 {
+		return field[:i], field[i+1:]
+	}
+	return field, ""
+}
+
+func newMtreeEntry(path string, keywords map[string]string) (MtreeEntry, error) {
+	entry := MtreeEntry{Path: path, Type: keywords["type"]}
+	var err error
+
+	if v, ok := keywords["mode"]; ok {
+		mode, err := strconv.ParseUint(v, 8, 32)
+		if err != nil {
+			return entry, fmt.Errorf("invalid mode %s", v)
+		}
+		entry.Mode = uint32(mode)
+	}
+	if v, ok := keywords["uid"]; ok {
+		if entry.UID, err = strconv.Atoi(v); err != nil {
+			return entry, fmt.Errorf("invalid uid %s", v)
+		}
+	}
+	if v, ok := keywords["gid"]; ok {
+		if entry.GID, err = strconv.Atoi(v); err != nil {
+			return entry, fmt.Errorf("invalid gid %s", v)
+		}
+	}
+	if v, ok := keywords["size"]; ok {
+		if entry.Size, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return entry, fmt.Errorf("invalid size %s", v)
+		}
+	}
+	if v, ok := keywords["time"]; ok {
+		sec, nsec := v, "0"
+		if i := strings.Index(v, "."); i >= 0 {
+			sec, nsec = v[:i], v[i+1:]
+		}
+		s, err1 := strconv.ParseInt(sec, 10, 64)
+		ns, err2 := strconv.ParseInt(nsec, 10, 64)
+		if err1 != nil || err2 != nil {
+			return entry, fmt.Errorf("invalid time %s", v)
+		}
+		entry.ModTime = time.Unix(s, ns)
+	}
+	if v, ok := keywords["link"]; ok {
+		if entry.Link, err = unescapeMtree(v); err != nil {
+			return entry, err
+		}
+	}
+	entry.SHA256 = keywords["sha256digest"]
+	entry.MD5 = keywords["md5digest"]
+
+	return entry, nil
+}
+
+// unescapeMtree decodes the octal escapes, such as \040 for a space, used
+// by mtree for special characters.
+func unescapeMtree(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			c, _ := strconv.ParseUint(s[i+1:i+4], 8, 8)
+			b.WriteByte(byte(c))
+			i += 3
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\\' {
+			b.WriteByte('\\')
+			i++
+			continue
+		}
+		return "", fmt.Errorf("invalid escape in %s", s)
+	}
+	return b.String(), nil
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
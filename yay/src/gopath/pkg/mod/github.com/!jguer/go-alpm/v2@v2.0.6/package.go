@@ -43,6 +43,43 @@ type PackageList struct {
 	handle Handle
 }
 
+// LoadPkg loads a package archive file. If full is false only the package
+// metadata is read, otherwise the file list is loaded too. The archive
+// signature is checked according to level. The package must be released
+// with Free once it is no longer needed, unless it was added to a
+// transaction, in which case it is released with the transaction.
+func (h *Handle) LoadPkg(path string, full bool, level SigLevel) (IPackage, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	cFull := C.int(0)
+	if full {
+		cFull = C.int(1)
+	}
+
+	var pkg *C.alpm_pkg_t
+	if C.alpm_pkg_load(h.ptr, cPath, cFull, C.int(level), &pkg) != 0 {
+		return nil, h.LastError()
+	}
+
+	return &Package{pkg, *h}, nil
+}
+
+// Free releases a package loaded with LoadPkg. Packages that come from a
+// database are owned by libalpm and are left untouched.
+func (pkg *Package) Free() error {
+	if pkg.Origin() != FromFile {
+		return nil
+	}
+
+	if C.alpm_pkg_free(pkg.pmpkg) != 0 {
+		return pkg.handle.LastError()
+	}
+	pkg.pmpkg = nil
+
+	return nil
+}
+
 // ForEach executes an action on each package of the PackageList.
 func (l PackageList) ForEach(f func(IPackage) error) error {
 	return l.forEach(func(p unsafe.Pointer) error {
@@ -75,27 +112,6 @@ func (l PackageList) SortBySize() IPackageList {
 	return PackageList{pkgCache, l.handle}
 }
 
-// DependList describes a linkedlist of dependency type packages.
-type DependList struct{ *list }
-
-// ForEach executes an action on each package of the DependList.
-func (l DependList) ForEach(f func(Depend) error) error {
-	return l.forEach(func(p unsafe.Pointer) error {
-		dep := convertDepend((*C.alpm_depend_t)(p))
-		return f(dep)
-	})
-}
-
-// Slice converts the DependList to a Depend Slice.
-func (l DependList) Slice() []Depend {
-	slice := []Depend{}
-	_ = l.ForEach(func(dep Depend) error {
-		slice = append(slice, dep)
-		return nil
-	})
-	return slice
-}
-
 func (pkg *Package) FileName() string {
 	return C.GoString(C.alpm_pkg_get_filename(pkg.pmpkg))
 }
@@ -120,7 +136,7 @@ func (pkg *Package) Architecture() string {
 // Backup returns a list of package backups.
 func (pkg *Package) Backup() BackupList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_backup(pkg.pmpkg))
-	return BackupList{(*list)(ptr)}
+	return convertBackupList((*list)(ptr))
 }
 
 // BuildDate returns the BuildDate of the package.
@@ -132,7 +148,7 @@ func (pkg *Package) BuildDate() time.Time {
 // Conflicts returns the conflicts of the package as a DependList.
 func (pkg *Package) Conflicts() DependList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_conflicts(pkg.pmpkg))
-	return DependList{(*list)(ptr)}
+	return convertDependList((*list)(ptr))
 }
 
 // DB returns the package's origin database.
@@ -147,25 +163,25 @@ func (pkg *Package) DB() IDB {
 // Depends returns the package's dependency list.
 func (pkg *Package) Depends() DependList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_depends(pkg.pmpkg))
-	return DependList{(*list)(ptr)}
+	return convertDependList((*list)(ptr))
 }
 
 // Depends returns the package's optional dependency list.
 func (pkg *Package) OptionalDepends() DependList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_optdepends(pkg.pmpkg))
-	return DependList{(*list)(ptr)}
+	return convertDependList((*list)(ptr))
 }
 
 // Depends returns the package's check dependency list.
 func (pkg *Package) CheckDepends() DependList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_checkdepends(pkg.pmpkg))
-	return DependList{(*list)(ptr)}
+	return convertDependList((*list)(ptr))
 }
 
 // Depends returns the package's make dependency list.
 func (pkg *Package) MakeDepends() DependList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_makedepends(pkg.pmpkg))
-	return DependList{(*list)(ptr)}
+	return convertDependList((*list)(ptr))
 }
 
 // Description returns the package's description.
@@ -181,13 +197,16 @@ func (pkg *Package) Files() []File {
 
 // ContainsFile checks if the path is in the package filelist
 func (pkg *Package) ContainsFile(path string) (File, error) {
-	return convertFile(C.alpm_filelist_contains(C.alpm_pkg_get_files(pkg.pmpkg), C.CString(path)))
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	return convertFile(C.alpm_filelist_contains(C.alpm_pkg_get_files(pkg.pmpkg), cPath))
 }
 
 // Groups returns the groups the package belongs to.
 func (pkg *Package) Groups() StringList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_groups(pkg.pmpkg))
-	return StringList{(*list)(ptr)}
+	return convertStringList((*list)(ptr))
 }
 
 // ISize returns the package installed size.
@@ -205,7 +224,7 @@ func (pkg *Package) InstallDate() time.Time {
 // Licenses returns the package license list.
 func (pkg *Package) Licenses() StringList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_licenses(pkg.pmpkg))
-	return StringList{(*list)(ptr)}
+	return convertStringList((*list)(ptr))
 }
 
 // SHA256Sum returns package SHA256Sum.
@@ -231,7 +250,7 @@ func (pkg *Package) Packager() string {
 // Provides returns DependList of packages provides by package.
 func (pkg *Package) Provides() DependList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_provides(pkg.pmpkg))
-	return DependList{(*list)(ptr)}
+	return convertDependList((*list)(ptr))
 }
 
 // Reason returns package install reason.
@@ -240,6 +259,17 @@ func (pkg *Package) Reason() PkgReason {
 	return PkgReason(reason)
 }
 
+// SetReason changes the install reason of a package of the local database,
+// like pacman -D --asdeps and --asexplicit. Like pacman, callers should hold
+// the database lock with TransInit while changing reasons.
+func (pkg *Package) SetReason(reason PkgReason) error {
+	if C.alpm_pkg_set_reason(pkg.pmpkg, C.alpm_pkgreason_t(reason)) != 0 {
+		return pkg.handle.LastError()
+	}
+
+	return nil
+}
+
 // Origin returns package origin.
 func (pkg *Package) Origin() PkgFrom {
 	origin := C.alpm_pkg_get_origin(pkg.pmpkg)
@@ -249,7 +279,7 @@ func (pkg *Package) Origin() PkgFrom {
 // Replaces returns a DependList with the packages this package replaces.
 func (pkg *Package) Replaces() DependList {
 	ptr := unsafe.Pointer(C.alpm_pkg_get_replaces(pkg.pmpkg))
-	return DependList{(*list)(ptr)}
+	return convertDependList((*list)(ptr))
 }
 
 // Size returns the packed package size.
@@ -258,6 +288,13 @@ func (pkg *Package) Size() int64 {
 	return int64(t)
 }
 
+// DownloadSize returns the number of bytes left to download for a sync
+// package, which is 0 if it is already in a cache directory.
+func (pkg *Package) DownloadSize() int64 {
+	t := C.alpm_pkg_download_size(pkg.pmpkg)
+	return int64(t)
+}
+
 // URL returns the upstream URL of the package.
 func (pkg *Package) URL() string {
 	return C.GoString(C.alpm_pkg_get_url(pkg.pmpkg))
@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 // package_test.go - Tests for package.go
 //
 // Copyright (c) 2013 The go-alpm Authors
@@ -7,8 +10,12 @@
 package alpm
 
 import (
+	"archive/tar"
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
@@ -124,3 +131,120 @@ func TestPkgFiles(t *testing.T) {
 		t.Errorf("File should be nil but got %v", err)
 	}
 }
+
+// testPkg describes a package archive built by writeTestPkg.
+type testPkg struct {
+	Name      string
+	Version   string
+	Depends   []string
+	Provides  []string
+	Conflicts []string
+	Replaces  []string
+	Backup    []string
+	Files     map[string]string
+	ISize     int64
+}
+
+// writeTestPkg writes an uncompressed package archive into dir and returns
+// its path.
+func writeTestPkg(t *testing.T, dir string, pkg testPkg) string {
+	t.Helper()
+
+	pkginfo := &strings.Builder{}
+	fmt.Fprintf(pkginfo, "pkgname = %s\npkgbase = %s\npkgver = %s\n", pkg.Name, pkg.Name, pkg.Version)
+	fmt.Fprintf(pkginfo, "pkgdesc = go-alpm test package\narch = any\nbuilddate = 1627862400\n")
+	fmt.Fprintf(pkginfo, "packager = go-alpm tests\nlicense = MIT\n")
+	if pkg.ISize > 0 {
+		fmt.Fprintf(pkginfo, "size = %d\n", pkg.ISize)
+	}
+	for _, dep := range pkg.Depends {
+		fmt.Fprintf(pkginfo, "depend = %s\n", dep)
+	}
+	for _, prov := range pkg.Provides {
+		fmt.Fprintf(pkginfo, "provides = %s\n", prov)
+	}
+	for _, conflict := range pkg.Conflicts {
+		fmt.Fprintf(pkginfo, "conflict = %s\n", conflict)
+	}
+	for _, replace := range pkg.Replaces {
+		fmt.Fprintf(pkginfo, "replaces = %s\n", replace)
+	}
+	for _, backup := range pkg.Backup {
+		fmt.Fprintf(pkginfo, "backup = %s\n", backup)
+	}
+
+	path := filepath.Join(dir, pkg.Name+"-"+pkg.Version+"-any.pkg.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	write := func(name, content string) {
+		hdr := &tar.Header{
+			Name: name, Mode: 0o644, Size: int64(len(content)), ModTime: time.Unix(1627862400, 0),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".PKGINFO", pkginfo.String())
+	for name, content := range pkg.Files {
+		write(name, content)
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadPkg(t *testing.T) {
+	h := initTestRoot(t)
+	path := writeTestPkg(t, t.TempDir(), testPkg{
+		Name:    "go-alpm-test",
+		Version: "1.0-1",
+		Depends: []string{"glibc>=2.12"},
+		Backup:  []string{"etc/go-alpm-test.conf"},
+		Files: map[string]string{
+			"etc/go-alpm-test.conf": "key = value\n",
+			"usr/bin/go-alpm-test":  "#!/bin/sh\n",
+		},
+	})
+
+	pkg, err := h.LoadPkg(path, true, 0)
+	if err != nil {
+		t.Fatalf("LoadPkg failed: %s", err)
+	}
+	defer pkg.(*Package).Free()
+
+	if pkg.Name() != "go-alpm-test" || pkg.Version() != "1.0-1" {
+		t.Errorf("got %s %s, want go-alpm-test 1.0-1", pkg.Name(), pkg.Version())
+	}
+	if pkg.Origin() != FromFile {
+		t.Errorf("got origin %d, want FromFile", pkg.Origin())
+	}
+	if deps := pkg.Depends().Slice(); len(deps) != 1 || deps[0].String() != "glibc>=2.12" {
+		t.Errorf("got depends %v", deps)
+	}
+	if backup := pkg.Backup().Slice(); len(backup) != 1 || backup[0].Name != "etc/go-alpm-test.conf" {
+		t.Errorf("got backup %v", backup)
+	}
+	if _, err := pkg.ContainsFile("usr/bin/go-alpm-test"); err != nil {
+		t.Errorf("file list is missing usr/bin/go-alpm-test")
+	}
+}
+
+func TestLoadPkgMissing(t *testing.T) {
+	h := initTestRoot(t)
+
+	if _, err := h.LoadPkg(filepath.Join(t.TempDir(), "missing.pkg.tar.zst"), false, 0); err == nil {
+		t.Errorf("LoadPkg of a missing file should fail")
+	}
+}
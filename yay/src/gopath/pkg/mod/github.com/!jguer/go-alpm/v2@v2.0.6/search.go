@@ -0,0 +1,198 @@
+// search.go - Package, group and provider queries with detailed results.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// SearchOptions configures SearchDB.
+type SearchOptions struct {
+	// Fields selects the fields matched against, SearchAll if zero.
+	Fields SearchField
+	// Literal matches the terms as plain substrings instead of regular
+	// expressions.
+	Literal bool
+	// CaseSensitive disables case folding, which DB.Search always applies.
+	CaseSensitive bool
+}
+
+// SearchResult is a package matched by a search. Matched holds every field
+// in which one of the terms was found.
+type SearchResult struct {
+	DB      IDB
+	Package IPackage
+	Matched SearchField
+}
+
+// SearchDB returns the packages of db matching every term, like DB.Search,
+// along with the fields that matched. Unlike DB.Search, an invalid regular
+// expression is reported as an error.
+func SearchDB(db IDB, terms []string, opts SearchOptions) ([]SearchResult, error) {
+	res, err := searchRegexps(terms, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := opts.Fields
+	if fields == 0 {
+		fields = SearchAll
+	}
+
+	results := []SearchResult{}
+	for _, pkg := range db.PkgCache().Slice() {
+		if matched := matchPkg(pkg, res, fields); matched != 0 {
+			results = append(results, SearchResult{db, pkg, matched})
+		}
+	}
+
+	return results, nil
+}
+
+// SearchDBs runs SearchDB on every database of dbs, in order.
+func SearchDBs(dbs IDBList, terms []string, opts SearchOptions) ([]SearchResult, error) {
+	results := []SearchResult{}
+	for _, db := range dbs.Slice() {
+		dbResults, err := SearchDB(db, terms, opts)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, dbResults...)
+	}
+	return results, nil
+}
+
+func searchRegexps(terms []string, opts SearchOptions) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, len(terms))
+	for i, term := range terms {
+		if opts.Literal {
+			term = regexp.QuoteMeta(term)
+		}
+		if !opts.CaseSensitive {
+			term = "(?i)" + term
+		}
+
+		re, err := regexp.Compile(term)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = re
+	}
+	return res, nil
+}
+
+// matchPkg returns the fields of pkg matched by res, or 0 if one of the
+// expressions matches none of them.
+func matchPkg(pkg IPackage, res []*regexp.Regexp, fields SearchField) SearchField {
+	var provides []Depend
+	if fields&SearchProvides != 0 {
+		provides = pkg.Provides().Slice()
+	}
+
+	var all SearchField
+	for _, re := range res {
+		var matched SearchField
+		if fields&SearchName != 0 && re.MatchString(pkg.Name()) {
+			matched |= SearchName
+		}
+		if fields&SearchDescription != 0 && re.MatchString(pkg.Description()) {
+			matched |= SearchDescription
+		}
+		for _, prov := range provides {
+			if re.MatchString(prov.Name) {
+				matched |= SearchProvides
+				break
+			}
+		}
+
+		if matched == 0 {
+			return 0
+		}
+		all |= matched
+	}
+
+	return all
+}
+
+// GroupInfo is a package group and its number of members.
+type GroupInfo struct {
+	Name  string
+	Count int
+}
+
+// Groups returns the groups of the packages of dbs, sorted by name. Like
+// FindGroupPkgs, a package name found in several databases is counted once,
+// but ignored packages are counted too.
+func Groups(dbs IDBList) []GroupInfo {
+	members := map[string]map[string]bool{}
+	for _, db := range dbs.Slice() {
+		for _, pkg := range db.PkgCache().Slice() {
+			for _, group := range pkg.Groups().Slice() {
+				if members[group] == nil {
+					members[group] = map[string]bool{}
+				}
+				members[group][pkg.Name()] = true
+			}
+		}
+	}
+
+	groups := make([]GroupInfo, 0, len(members))
+	for name, pkgs := range members {
+		groups = append(groups, GroupInfo{name, len(pkgs)})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+
+	return groups
+}
+
+// FindProviders returns every package of dbs satisfying depstring, where
+// FindSatisfier only returns the one libalpm would pick. Packages named
+// after the dependency come first, then the other providers, each in
+// database order. Ignored packages are left out.
+func FindProviders(dbs IDBList, depstring string) []IPackage {
+	dep := vercmp.ParseDepend(depstring)
+
+	literals := []IPackage{}
+	providers := []IPackage{}
+	for _, db := range dbs.Slice() {
+		for _, pkg := range db.PkgCache().Slice() {
+			if pkg.ShouldIgnore() {
+				continue
+			}
+			if pkg.Name() == dep.Name {
+				if dep.Satisfies(pkg.Name(), pkg.Version()) {
+					literals = append(literals, pkg)
+				}
+				continue
+			}
+			if providesDep(pkg, dep) {
+				providers = append(providers, pkg)
+			}
+		}
+	}
+
+	return append(literals, providers...)
+}
+
+// providesDep reports whether one of the provisions of pkg satisfies dep.
+// An unversioned provision only satisfies unversioned dependencies.
+func providesDep(pkg IPackage, dep vercmp.Depend) bool {
+	for _, prov := range pkg.Provides().Slice() {
+		if prov.Name != dep.Name {
+			continue
+		}
+		if dep.Satisfies(prov.Name, prov.Version) {
+			return true
+		}
+	}
+	return false
+}
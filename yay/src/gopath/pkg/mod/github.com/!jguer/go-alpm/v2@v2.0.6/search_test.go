@@ -0,0 +1,123 @@
+// search_test.go - Tests for the search, group and provider queries.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm_test
+
+import (
+	"reflect"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/alpmtest"
+)
+
+func searchTestDBs() *alpmtest.DBList {
+	core := alpmtest.NewDB("core",
+		alpmtest.NewPackage("bash", "5.1.008-1").WithProvides("sh").WithDescription("The GNU Bourne Again shell"),
+		alpmtest.NewPackage("make", "4.3-3").WithGroups("base-devel").WithDescription("GNU make utility"),
+		alpmtest.NewPackage("sed", "4.8-1").WithGroups("base-devel").WithIgnore(true),
+	)
+	extra := alpmtest.NewDB("extra",
+		alpmtest.NewPackage("make", "4.4-1").WithGroups("base-devel"),
+		alpmtest.NewPackage("zsh", "5.8-1").WithProvides("sh=5.8").WithDescription("A very advanced shell"),
+		alpmtest.NewPackage("sh", "1.0-1").WithDescription("c++ shell"),
+		alpmtest.NewPackage("busybox", "1.33-1").WithProvides("sh=1.33").WithIgnore(true),
+		alpmtest.NewPackage("plasma-meta", "5.22-1").WithGroups("plasma"),
+	)
+
+	return alpmtest.NewDBList(core, extra)
+}
+
+func TestSearchDBs(t *testing.T) {
+	dbs := searchTestDBs()
+
+	type result struct {
+		Name    string
+		Matched alpm.SearchField
+	}
+
+	tests := []struct {
+		name  string
+		terms []string
+		opts  alpm.SearchOptions
+		want  []result
+	}{
+		{"all fields", []string{"^sh"}, alpm.SearchOptions{}, []result{
+			{"bash", alpm.SearchProvides},
+			{"zsh", alpm.SearchProvides},
+			{"sh", alpm.SearchName},
+			{"busybox", alpm.SearchProvides},
+		}},
+		{"every term", []string{"shell", "GNU"}, alpm.SearchOptions{}, []result{
+			{"bash", alpm.SearchDescription},
+		}},
+		{"several fields", []string{"sh"}, alpm.SearchOptions{Fields: alpm.SearchName | alpm.SearchDescription}, []result{
+			{"bash", alpm.SearchName | alpm.SearchDescription},
+			{"zsh", alpm.SearchName | alpm.SearchDescription},
+			{"sh", alpm.SearchName | alpm.SearchDescription},
+		}},
+		{"case sensitive", []string{"gnu"}, alpm.SearchOptions{CaseSensitive: true}, []result{}},
+		{"literal", []string{"C++"}, alpm.SearchOptions{Literal: true}, []result{
+			{"sh", alpm.SearchDescription},
+		}},
+	}
+
+	for _, tt := range tests {
+		results, err := alpm.SearchDBs(dbs, tt.terms, tt.opts)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+
+		got := []result{}
+		for _, r := range results {
+			got = append(got, result{r.Package.Name(), r.Matched})
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := alpm.SearchDBs(dbs, []string{"c++"}, alpm.SearchOptions{}); err == nil {
+		t.Errorf("invalid regular expression did not fail")
+	}
+	if s := (alpm.SearchName | alpm.SearchProvides).String(); s != "name,provides" {
+		t.Errorf("unexpected field string %q", s)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	got := alpm.Groups(searchTestDBs())
+	want := []alpm.GroupInfo{{"base-devel", 2}, {"plasma", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindProviders(t *testing.T) {
+	dbs := searchTestDBs()
+
+	tests := []struct {
+		dep  string
+		want []string
+	}{
+		{"sh", []string{"extra/sh", "core/bash", "extra/zsh"}},
+		{"sh>=5", []string{"extra/zsh"}},
+		{"make", []string{"core/make", "extra/make"}},
+		{"make>4.3", []string{"extra/make"}},
+		{"sed", []string{}},
+	}
+
+	for _, tt := range tests {
+		got := []string{}
+		for _, pkg := range alpm.FindProviders(dbs, tt.dep) {
+			got = append(got, pkg.DB().Name()+"/"+pkg.Name())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.dep, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// signature.go - Functions for PGP signature checking.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+// #include <alpm.h>
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+func convertTime(t C.alpm_time_t) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t), 0)
+}
+
+func convertSigList(siglist *C.alpm_siglist_t) SigList {
+	size := int(siglist.count)
+	if size == 0 {
+		return SigList{}
+	}
+
+	cResults := (*[1 << 20]C.alpm_sigresult_t)(unsafe.Pointer(siglist.results))[:size:size]
+	results := make(SigList, size)
+
+	for i := range cResults {
+		key := &cResults[i].key
+		results[i] = SigResult{
+			Key: PGPKey{
+				Fingerprint: C.GoString(key.fingerprint),
+				UID:         C.GoString(key.uid),
+				Name:        C.GoString(key.name),
+				Email:       C.GoString(key.email),
+				Created:     convertTime(key.created),
+				Expires:     convertTime(key.expires),
+				Length:      uint(key.length),
+				Revoked:     key.revoked != 0,
+				PubkeyAlgo:  byte(key.pubkey_algo),
+			},
+			Status:   SigStatus(cResults[i].status),
+			Validity: SigValidity(cResults[i].validity),
+		}
+	}
+
+	return results
+}
+
+// CheckPGPSignature checks the PGP signature of the package. The results of
+// every signature found are returned, also when one of them is not valid,
+// in which case the error is set too.
+func (pkg *Package) CheckPGPSignature() (SigList, error) {
+	var siglist C.alpm_siglist_t
+	defer C.alpm_siglist_cleanup(&siglist)
+
+	ret := C.alpm_pkg_check_pgp_signature(pkg.pmpkg, &siglist)
+	results := convertSigList(&siglist)
+	if ret != 0 {
+		return results, pkg.handle.LastError()
+	}
+
+	return results, nil
+}
+
+// CheckPGPSignature checks the PGP signature of the database. The results of
+// every signature found are returned, also when one of them is not valid,
+// in which case the error is set too.
+func (db *DB) CheckPGPSignature() (SigList, error) {
+	var siglist C.alpm_siglist_t
+	defer C.alpm_siglist_cleanup(&siglist)
+
+	ret := C.alpm_db_check_pgp_signature(db.ptr, &siglist)
+	results := convertSigList(&siglist)
+	if ret != 0 {
+		return results, db.handle.LastError()
+	}
+
+	return results, nil
+}
+
+// ParseSigLevel applies pacman.conf SigLevel values such as
+// "Required DatabaseOptional" on top of base, the same way pacman does.
+// Use DefaultSigLevel as base for the [options] section and the parsed
+// global level for repositories.
+func ParseSigLevel(base SigLevel, values ...string) (SigLevel, error) {
+	level := base &^ SigUseDefault
+
+	for _, value := range values {
+		for _, field := range strings.Fields(value) {
+			word := field
+			pkg, db := true, true
+
+			switch {
+			case strings.HasPrefix(word, "Package"):
+				word = strings.TrimPrefix(word, "Package")
+				db = false
+			case strings.HasPrefix(word, "Database"):
+				word = strings.TrimPrefix(word, "Database")
+				pkg = false
+			}
+
+			var set, unset SigLevel
+			switch word {
+			case "Never":
+				unset = SigPackage
+			case "Optional":
+				set = SigPackage | SigPackageOptional
+			case "Required":
+				set, unset = SigPackage, SigPackageOptional
+			case "TrustedOnly":
+				unset = SigPackageMarginalOk | SigPackageUnknownOk
+			case "TrustAll":
+				set = SigPackageMarginalOk | SigPackageUnknownOk
+			default:
+				return base, fmt.Errorf("invalid value for SigLevel: %s", field)
+			}
+
+			if pkg {
+				level = level&^unset | set
+			}
+			if db {
+				// database flags are the package flags shifted by 10 bits
+				level = level&^(unset<<10) | set<<10
+			}
+		}
+	}
+
+	return level, nil
+}
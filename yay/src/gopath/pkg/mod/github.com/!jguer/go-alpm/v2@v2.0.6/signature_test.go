@@ -0,0 +1,102 @@
+//go:build cgo
+// +build cgo
+
+// signature_test.go - Tests for signature.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"testing"
+)
+
+func TestParseSigLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    SigLevel
+		values  []string
+		want    SigLevel
+		wantErr bool
+	}{
+		{
+			name: "no values",
+			base: DefaultSigLevel,
+			want: DefaultSigLevel,
+		},
+		{
+			name:   "required database optional",
+			base:   DefaultSigLevel,
+			values: []string{"Required DatabaseOptional"},
+			want:   SigPackage | SigDatabase | SigDatabaseOptional,
+		},
+		{
+			name:   "split values",
+			base:   DefaultSigLevel,
+			values: []string{"Required", "DatabaseOptional"},
+			want:   SigPackage | SigDatabase | SigDatabaseOptional,
+		},
+		{
+			name:   "never",
+			base:   DefaultSigLevel,
+			values: []string{"Never"},
+			want:   SigPackageOptional | SigDatabaseOptional,
+		},
+		{
+			name:   "package trust all",
+			base:   SigPackage | SigDatabase,
+			values: []string{"PackageTrustAll"},
+			want:   SigPackage | SigPackageMarginalOk | SigPackageUnknownOk | SigDatabase,
+		},
+		{
+			name:   "trusted only",
+			base:   SigPackage | SigPackageMarginalOk | SigDatabase | SigDatabaseUnknownOk,
+			values: []string{"TrustedOnly"},
+			want:   SigPackage | SigDatabase,
+		},
+		{
+			name:   "use default is dropped",
+			base:   SigUseDefault,
+			values: []string{"DatabaseRequired"},
+			want:   SigDatabase,
+		},
+		{
+			name:    "invalid",
+			base:    DefaultSigLevel,
+			values:  []string{"Required Sometimes"},
+			want:    DefaultSigLevel,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSigLevel(tt.base, tt.values...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %b, want %b", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPGPSignatureUnsigned(t *testing.T) {
+	h := initTestRoot(t)
+	path := writeTestPkg(t, t.TempDir(), testPkg{Name: "go-alpm-test", Version: "1.0-1"})
+
+	pkg, err := h.LoadPkg(path, false, 0)
+	if err != nil {
+		t.Fatalf("LoadPkg failed: %s", err)
+	}
+	defer pkg.(*Package).Free()
+
+	sigs, err := pkg.CheckPGPSignature()
+	if err == nil {
+		t.Errorf("unsigned package has a valid signature")
+	}
+	if len(sigs) != 0 {
+		t.Errorf("got %d signatures for an unsigned package", len(sigs))
+	}
+}
@@ -0,0 +1,243 @@
+//go:build cgo
+// +build cgo
+
+// sysupgrade.go - Plan system upgrades without committing them.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// SysupgradeOptions configures PlanSysupgrade.
+type SysupgradeOptions struct {
+	// EnableDowngrade plans downgrades of packages newer than in the sync
+	// databases, like pacman -Suu.
+	EnableDowngrade bool
+	// ResolveDeps prepares the transaction, so that new dependencies of the
+	// upgrades are planned too and unresolvable upgrades are reported as a
+	// *TransError.
+	ResolveDeps bool
+	// Hold lists packages that must not be upgraded, on top of IgnorePkg
+	// and IgnoreGroup.
+	Hold []string
+}
+
+// PlannedUpgrade is an installed package that would be upgraded, or
+// downgraded if Downgrade is set.
+type PlannedUpgrade struct {
+	Local     IPackage
+	Sync      IPackage
+	Downgrade bool
+}
+
+// PlannedReplacement is a sync package that would replace installed ones.
+type PlannedReplacement struct {
+	Sync     IPackage
+	Replaces []IPackage
+}
+
+// HeldPackage is an installed package that would not be upgraded although
+// its sync version differs.
+type HeldPackage struct {
+	Local  IPackage
+	Sync   IPackage
+	Reason HoldReason
+}
+
+// SysupgradePlan describes what a system upgrade would do. The packages
+// belong to the databases of the handle and stay valid until they are
+// unregistered or refreshed.
+type SysupgradePlan struct {
+	Upgrades     []PlannedUpgrade
+	Replacements []PlannedReplacement
+	// Installs holds new dependencies, only with ResolveDeps.
+	Installs []IPackage
+	// Removals holds installed packages removed without being replaced.
+	Removals []IPackage
+	Held     []HeldPackage
+
+	// DownloadSize is the number of bytes left to download.
+	DownloadSize int64
+	// InstallSizeDelta is the change of the installed size.
+	InstallSizeDelta int64
+}
+
+// PlanSysupgrade computes the system upgrade pacman -Su would perform. The
+// upgrade runs in a transaction that is released before returning, so
+// nothing is downloaded or installed. No other transaction may be active.
+// Like pacman, replacements are only planned if the question callback
+// accepts them.
+func (h *Handle) PlanSysupgrade(opts SysupgradeOptions) (*SysupgradePlan, error) {
+	localDB, err := h.LocalDB()
+	if err != nil {
+		return nil, err
+	}
+	syncDBs, err := h.SyncDBs()
+	if err != nil {
+		return nil, err
+	}
+
+	// held packages are ignored for the duration of the plan, so that they
+	// do not end up in the transaction as dependencies either. An empty
+	// IgnorePkg list is reported with a stale error, hence it is not checked.
+	ignored, _ := h.IgnorePkgs()
+	ignoring := map[string]bool{}
+	for _, name := range ignored.Slice() {
+		ignoring[name] = true
+	}
+
+	held := map[string]bool{}
+	for _, name := range opts.Hold {
+		held[name] = true
+		if ignoring[name] {
+			continue
+		}
+		ignoring[name] = true
+		if err := h.AddIgnorePkg(name); err != nil {
+			return nil, err
+		}
+		defer h.RemoveIgnorePkg(name)
+	}
+
+	if err := h.TransInit(TransFlagNoLock); err != nil {
+		return nil, err
+	}
+	defer h.TransRelease()
+
+	if err := h.SyncSysupgrade(opts.EnableDowngrade); err != nil {
+		return nil, err
+	}
+	if opts.ResolveDeps {
+		if err := h.TransPrepare(); err != nil {
+			return nil, err
+		}
+	}
+
+	adds := h.TransGetAdd().Slice()
+
+	// libalpm only fills the removals with the replaced packages when the
+	// transaction is prepared, otherwise they are found here.
+	var removals []IPackage
+	if opts.ResolveDeps {
+		removals = h.TransGetRemove().Slice()
+	} else {
+		removals = replacedPkgs(localDB, adds)
+	}
+
+	plan := &SysupgradePlan{}
+	planned := map[string]bool{}
+	replaced := make([]bool, len(removals))
+
+	for _, spkg := range adds {
+		planned[spkg.Name()] = true
+		plan.InstallSizeDelta += spkg.ISize()
+		if p, ok := spkg.(*Package); ok {
+			plan.DownloadSize += p.DownloadSize()
+		}
+
+		if lpkg := localDB.Pkg(spkg.Name()); lpkg != nil {
+			plan.InstallSizeDelta -= lpkg.ISize()
+			plan.Upgrades = append(plan.Upgrades, PlannedUpgrade{
+				Local:     lpkg,
+				Sync:      spkg,
+				Downgrade: VerCmp(spkg.Version(), lpkg.Version()) < 0,
+			})
+			continue
+		}
+
+		replacement := PlannedReplacement{Sync: spkg}
+		for i, rpkg := range removals {
+			if !replaced[i] && replacesPkg(spkg, rpkg) {
+				replaced[i] = true
+				replacement.Replaces = append(replacement.Replaces, rpkg)
+			}
+		}
+
+		if len(replacement.Replaces) > 0 {
+			plan.Replacements = append(plan.Replacements, replacement)
+		} else {
+			plan.Installs = append(plan.Installs, spkg)
+		}
+	}
+
+	for i, rpkg := range removals {
+		planned[rpkg.Name()] = true
+		plan.InstallSizeDelta -= rpkg.ISize()
+		if !replaced[i] {
+			plan.Removals = append(plan.Removals, rpkg)
+		}
+	}
+
+	for _, lpkg := range localDB.PkgCache().Slice() {
+		if planned[lpkg.Name()] {
+			continue
+		}
+
+		spkg := firstSyncPkg(syncDBs, lpkg.Name())
+		if spkg == nil {
+			continue
+		}
+
+		cmp := VerCmp(lpkg.Version(), spkg.Version())
+		switch {
+		case cmp < 0 && held[lpkg.Name()]:
+			plan.Held = append(plan.Held, HeldPackage{lpkg, spkg, HoldHeld})
+		case cmp < 0 && (lpkg.ShouldIgnore() || spkg.ShouldIgnore()):
+			plan.Held = append(plan.Held, HeldPackage{lpkg, spkg, HoldIgnored})
+		case cmp > 0 && !opts.EnableDowngrade:
+			plan.Held = append(plan.Held, HeldPackage{lpkg, spkg, HoldNewerLocally})
+		}
+	}
+
+	return plan, nil
+}
+
+// replacesPkg reports whether one of the replaces of spkg matches pkg.
+func replacesPkg(spkg, pkg IPackage) bool {
+	for _, dep := range spkg.Replaces().Slice() {
+		d := vercmp.Depend{Name: dep.Name, Version: dep.Version, Mod: vercmp.DepMod(dep.Mod)}
+		if d.Satisfies(pkg.Name(), pkg.Version()) {
+			return true
+		}
+	}
+	return false
+}
+
+// replacedPkgs returns the installed packages replaced by one of adds and
+// not upgraded themselves.
+func replacedPkgs(localDB IDB, adds []IPackage) []IPackage {
+	adding := map[string]bool{}
+	for _, spkg := range adds {
+		adding[spkg.Name()] = true
+	}
+
+	var replaced []IPackage
+	for _, lpkg := range localDB.PkgCache().Slice() {
+		if adding[lpkg.Name()] {
+			continue
+		}
+		for _, spkg := range adds {
+			if replacesPkg(spkg, lpkg) {
+				replaced = append(replaced, lpkg)
+				break
+			}
+		}
+	}
+	return replaced
+}
+
+// firstSyncPkg returns the package called name from the first database of
+// dbs holding it.
+func firstSyncPkg(dbs IDBList, name string) IPackage {
+	for _, db := range dbs.Slice() {
+		if pkg := db.Pkg(name); pkg != nil {
+			return pkg
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+//go:build cgo
+// +build cgo
+
+// sysupgrade_test.go - Tests for sysupgrade.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// installTestPkgs installs the packages into the local database only, with
+// the extra transaction flags.
+func installTestPkgs(t *testing.T, h *Handle, flags TransFlag, pkgs ...testPkg) {
+	t.Helper()
+
+	if err := h.TransInit(TransFlagDBOnly | flags); err != nil {
+		t.Fatalf("TransInit failed: %s", err)
+	}
+	for _, pkg := range loadTestPkgs(t, h, pkgs...) {
+		if err := h.TransAddPkg(pkg); err != nil {
+			t.Fatalf("TransAddPkg failed: %s", err)
+		}
+	}
+	if err := h.TransPrepare(); err != nil {
+		t.Fatalf("TransPrepare failed: %s", err)
+	}
+	if err := h.TransCommit(); err != nil {
+		t.Fatalf("TransCommit failed: %s", err)
+	}
+	if err := h.TransRelease(); err != nil {
+		t.Fatalf("TransRelease failed: %s", err)
+	}
+}
+
+func TestPlanSysupgrade(t *testing.T) {
+	h := initTestRoot(t)
+	installTestPkgs(t, h, 0,
+		testPkg{Name: "foo", Version: "1.0-1"},
+		testPkg{Name: "bar", Version: "1.0-1"},
+		testPkg{Name: "ign", Version: "1.0-1"},
+		testPkg{Name: "held", Version: "1.0-1"},
+		testPkg{Name: "newer", Version: "2.0-1"},
+		testPkg{Name: "old-name", Version: "1.0-1"},
+	)
+
+	mirror := t.TempDir()
+	writeTestSyncDB(t, mirror, "test",
+		testPkg{Name: "foo", Version: "1.1-1"},
+		testPkg{Name: "bar", Version: "1.0-1"},
+		testPkg{Name: "ign", Version: "1.1-1"},
+		testPkg{Name: "held", Version: "1.1-1"},
+		testPkg{Name: "newer", Version: "1.0-1"},
+		testPkg{Name: "new-name", Version: "1.0-1", Replaces: []string{"old-name"}},
+	)
+
+	db, err := h.RegisterSyncDB("test", 0)
+	if err != nil {
+		t.Fatalf("RegisterSyncDB failed: %s", err)
+	}
+	db.SetServers([]string{"file://" + mirror})
+	dbs, _ := h.SyncDBs()
+	if res := h.DBUpdate(dbs, false); res[0].Err != nil {
+		t.Fatalf("DBUpdate failed: %s", res[0].Err)
+	}
+
+	if err := h.AddIgnorePkg("ign"); err != nil {
+		t.Fatal(err)
+	}
+	h.SetQuestionCallback(func(ctx interface{}, q QuestionAny) {
+		q.SetAnswer(true)
+	}, nil)
+
+	plan, err := h.PlanSysupgrade(SysupgradeOptions{Hold: []string{"held"}})
+	if err != nil {
+		t.Fatalf("PlanSysupgrade failed: %s", err)
+	}
+
+	if len(plan.Upgrades) != 1 || plan.Upgrades[0].Sync.Name() != "foo" || plan.Upgrades[0].Downgrade {
+		t.Errorf("unexpected upgrades %+v", plan.Upgrades)
+	}
+	if len(plan.Replacements) != 1 || plan.Replacements[0].Sync.Name() != "new-name" ||
+		len(plan.Replacements[0].Replaces) != 1 || plan.Replacements[0].Replaces[0].Name() != "old-name" {
+		t.Errorf("unexpected replacements %+v", plan.Replacements)
+	}
+	if len(plan.Installs) != 0 || len(plan.Removals) != 0 {
+		t.Errorf("unexpected installs %v and removals %v", plan.Installs, plan.Removals)
+	}
+
+	held := map[string]HoldReason{}
+	for _, p := range plan.Held {
+		held[p.Local.Name()] = p.Reason
+	}
+	want := map[string]HoldReason{"ign": HoldIgnored, "held": HoldHeld, "newer": HoldNewerLocally}
+	if !reflect.DeepEqual(held, want) {
+		t.Errorf("got held packages %v, want %v", held, want)
+	}
+
+	if ignored, _ := h.IgnorePkgs(); !reflect.DeepEqual(ignored.Slice(), []string{"ign"}) {
+		t.Errorf("IgnorePkg was not restored: %v", ignored.Slice())
+	}
+	if err := h.TransInit(TransFlagNoLock); err != nil {
+		t.Errorf("transaction was not released: %s", err)
+	}
+	h.TransRelease()
+
+	plan, err = h.PlanSysupgrade(SysupgradeOptions{EnableDowngrade: true})
+	if err != nil {
+		t.Fatalf("PlanSysupgrade failed: %s", err)
+	}
+	downgrades := 0
+	for _, up := range plan.Upgrades {
+		if up.Downgrade && up.Local.Name() == "newer" {
+			downgrades++
+		}
+	}
+	if downgrades != 1 {
+		t.Errorf("newer was not downgraded: %+v", plan.Upgrades)
+	}
+}
+
+// TestPlanSysupgradeReplacement checks that replacements are planned with
+// the default options, where the transaction is not prepared.
+func TestPlanSysupgradeReplacement(t *testing.T) {
+	h := initTestRoot(t)
+	installTestPkgs(t, h, 0,
+		testPkg{Name: "foo", Version: "1.0-1", ISize: 100},
+		testPkg{Name: "old-name", Version: "1.0-1", ISize: 1000},
+	)
+
+	mirror := t.TempDir()
+	writeTestSyncDB(t, mirror, "test",
+		testPkg{Name: "foo", Version: "1.1-1", ISize: 150},
+		testPkg{Name: "new-name", Version: "1.0-1", Replaces: []string{"old-name"}, ISize: 3000},
+	)
+
+	db, err := h.RegisterSyncDB("test", 0)
+	if err != nil {
+		t.Fatalf("RegisterSyncDB failed: %s", err)
+	}
+	db.SetServers([]string{"file://" + mirror})
+	dbs, _ := h.SyncDBs()
+	if res := h.DBUpdate(dbs, false); res[0].Err != nil {
+		t.Fatalf("DBUpdate failed: %s", res[0].Err)
+	}
+	h.SetQuestionCallback(func(ctx interface{}, q QuestionAny) {
+		q.SetAnswer(true)
+	}, nil)
+
+	plan, err := h.PlanSysupgrade(SysupgradeOptions{})
+	if err != nil {
+		t.Fatalf("PlanSysupgrade failed: %s", err)
+	}
+
+	if len(plan.Replacements) != 1 || plan.Replacements[0].Sync.Name() != "new-name" ||
+		len(plan.Replacements[0].Replaces) != 1 || plan.Replacements[0].Replaces[0].Name() != "old-name" {
+		t.Errorf("unexpected replacements %+v", plan.Replacements)
+	}
+	if len(plan.Upgrades) != 1 || len(plan.Installs) != 0 || len(plan.Removals) != 0 {
+		t.Errorf("unexpected upgrades %v, installs %v and removals %v", plan.Upgrades, plan.Installs, plan.Removals)
+	}
+	if want := int64(150 - 100 + 3000 - 1000); plan.InstallSizeDelta != want {
+		t.Errorf("got install size delta %d, want %d", plan.InstallSizeDelta, want)
+	}
+}
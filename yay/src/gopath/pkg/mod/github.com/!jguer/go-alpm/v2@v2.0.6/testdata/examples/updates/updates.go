@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"log"
 
+	alpm "github.com/Jguer/go-alpm/v2"
 	paconf "github.com/Morganamilo/go-pacmanconf"
 )
 
@@ -25,27 +26,6 @@ func human(size int64) string {
 	return fmt.Sprintf("%d%s", size, "B")
 }
 
-func upgrades(h *alpm.Handle) ([]alpm.IPackage, error) {
-	localDb, err := h.LocalDB()
-	if err != nil {
-		return nil, err
-	}
-
-	syncDbs, err := h.SyncDBs()
-	if err != nil {
-		return nil, err
-	}
-
-	slice := []alpm.IPackage{}
-	for _, pkg := range localDb.PkgCache().Slice() {
-		newPkg := pkg.SyncNewVersion(syncDbs)
-		if newPkg != nil {
-			slice = append(slice, newPkg)
-		}
-	}
-	return slice, nil
-}
-
 func main() {
 	h, er := alpm.Initialize("/", "/var/lib/pacman")
 	if er != nil {
@@ -60,51 +40,23 @@ func main() {
 		return
 	}
 
-	/*
-		We have to configure alpm with pacman configuration
-		to load the repositories and other stuff
-	*/
-	for _, repo := range PacmanConfig.Repos {
-		db, err := h.RegisterSyncDB(repo.Name, 0)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		db.SetServers(repo.Servers)
-
-		/*
-			Configure repository usage to match with
-			the alpm library provided formats
-		*/
-		if len(repo.Usage) == 0 {
-			db.SetUsage(alpm.UsageAll)
-		}
-		for _, usage := range repo.Usage {
-			switch usage {
-			case "Sync":
-				db.SetUsage(alpm.UsageSync)
-			case "Search":
-				db.SetUsage(alpm.UsageSearch)
-			case "Install":
-				db.SetUsage(alpm.UsageInstall)
-			case "Upgrade":
-				db.SetUsage(alpm.UsageUpgrade)
-			case "All":
-				db.SetUsage(alpm.UsageAll)
-			}
-		}
+	if err := h.ApplyConfig(PacmanConfig); err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	upgrades, err := upgrades(h)
+	plan, err := h.PlanSysupgrade(alpm.SysupgradeOptions{})
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	var size int64 = 0
-	for _, pkg := range upgrades {
-		size += pkg.Size()
-		fmt.Printf("%s %s -> %s\n", pkg.Name(), pkg.Version(),
-			pkg.Version())
+	for _, up := range plan.Upgrades {
+		fmt.Printf("%s %s -> %s\n", up.Local.Name(), up.Local.Version(), up.Sync.Version())
+	}
+	for _, held := range plan.Held {
+		fmt.Printf("%s %s -> %s (%s)\n", held.Local.Name(), held.Local.Version(),
+			held.Sync.Version(), held.Reason)
 	}
-	fmt.Printf("Total Download Size: %s\n", human(size))
+	fmt.Printf("Total Download Size: %s\n", human(plan.DownloadSize))
+	fmt.Printf("Net Upgrade Size: %s\n", human(plan.InstallSizeDelta))
 }
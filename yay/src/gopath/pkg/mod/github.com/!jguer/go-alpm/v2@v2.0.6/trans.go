@@ -12,9 +12,84 @@ package alpm
 import "C"
 
 import (
+	"errors"
+	"strings"
 	"unsafe"
 )
 
+// TransError is returned by TransPrepare and TransCommit when libalpm reports
+// which packages, dependencies or files caused the failure.
+type TransError struct {
+	Code Error
+	// Packages holds the package or file names reported for invalid
+	// architecture, invalid package and checksum/signature failures.
+	Packages      []string
+	MissingDeps   []DepMissing
+	Conflicts     []Conflict
+	FileConflicts []FileConflict
+}
+
+func (e *TransError) Error() string {
+	details := make([]string, 0, len(e.Packages)+len(e.MissingDeps)+
+		len(e.Conflicts)+len(e.FileConflicts))
+
+	details = append(details, e.Packages...)
+	for _, miss := range e.MissingDeps {
+		details = append(details, miss.String())
+	}
+	for _, conflict := range e.Conflicts {
+		details = append(details, conflict.String())
+	}
+	for _, conflict := range e.FileConflicts {
+		details = append(details, conflict.String())
+	}
+
+	if len(details) == 0 {
+		return e.Code.Error()
+	}
+	return e.Code.Error() + ": " + strings.Join(details, ", ")
+}
+
+func (e *TransError) Unwrap() error {
+	return e.Code
+}
+
+// transError converts the data list filled by alpm_trans_prepare and
+// alpm_trans_commit into a TransError and frees it.
+func (h *Handle) transError(data *C.alpm_list_t) error {
+	err := h.LastError()
+	code, ok := err.(Error)
+	if !ok || data == nil {
+		return err
+	}
+	defer C.alpm_list_free(data)
+
+	transErr := &TransError{Code: code}
+	_ = (*list)(unsafe.Pointer(data)).forEach(func(p unsafe.Pointer) error {
+		switch code {
+		case C.ALPM_ERR_UNSATISFIED_DEPS:
+			miss := (*C.alpm_depmissing_t)(p)
+			transErr.MissingDeps = append(transErr.MissingDeps, convertDepMissing(miss))
+			C.alpm_depmissing_free(miss)
+		case C.ALPM_ERR_CONFLICTING_DEPS:
+			conflict := (*C.alpm_conflict_t)(p)
+			transErr.Conflicts = append(transErr.Conflicts, convertConflict(conflict))
+			C.alpm_conflict_free(conflict)
+		case C.ALPM_ERR_FILE_CONFLICTS:
+			conflict := (*C.alpm_fileconflict_t)(p)
+			transErr.FileConflicts = append(transErr.FileConflicts, convertFileConflict(conflict))
+			C.alpm_fileconflict_free(conflict)
+		case C.ALPM_ERR_PKG_INVALID_ARCH, C.ALPM_ERR_PKG_INVALID,
+			C.ALPM_ERR_PKG_INVALID_CHECKSUM, C.ALPM_ERR_PKG_INVALID_SIG:
+			transErr.Packages = append(transErr.Packages, C.GoString((*C.char)(p)))
+			C.free(p)
+		}
+		return nil
+	})
+
+	return transErr
+}
+
 func (h *Handle) TransInit(flags TransFlag) error {
 	ret := C.alpm_trans_init(h.ptr, C.int(flags))
 	if ret != 0 {
@@ -52,3 +127,71 @@ func (h *Handle) TransGetFlags() (TransFlag, error) {
 
 	return TransFlag(flags), nil
 }
+
+// TransAddPkg adds a package to the current transaction. The package must
+// come from a sync database or be loaded from a file.
+func (h *Handle) TransAddPkg(pkg IPackage) error {
+	alpmPkg, ok := pkg.(*Package)
+	if !ok {
+		return errors.New("package is not an alpm package")
+	}
+
+	ret := C.alpm_add_pkg(h.ptr, alpmPkg.pmpkg)
+	if ret != 0 {
+		return h.LastError()
+	}
+
+	return nil
+}
+
+// TransRemovePkg adds a package of the local database to the removal list of
+// the current transaction.
+func (h *Handle) TransRemovePkg(pkg IPackage) error {
+	alpmPkg, ok := pkg.(*Package)
+	if !ok {
+		return errors.New("package is not an alpm package")
+	}
+
+	ret := C.alpm_remove_pkg(h.ptr, alpmPkg.pmpkg)
+	if ret != 0 {
+		return h.LastError()
+	}
+
+	return nil
+}
+
+// TransPrepare resolves dependencies and checks for conflicts in the current
+// transaction. Dependency and conflict failures are returned as *TransError.
+func (h *Handle) TransPrepare() error {
+	var data *C.alpm_list_t
+
+	ret := C.alpm_trans_prepare(h.ptr, &data)
+	if ret != 0 {
+		return h.transError(data)
+	}
+
+	return nil
+}
+
+// TransCommit commits the prepared transaction. File conflicts and invalid
+// packages are returned as *TransError.
+func (h *Handle) TransCommit() error {
+	var data *C.alpm_list_t
+
+	ret := C.alpm_trans_commit(h.ptr, &data)
+	if ret != 0 {
+		return h.transError(data)
+	}
+
+	return nil
+}
+
+// TransInterrupt asks libalpm to abort the transaction being committed.
+func (h *Handle) TransInterrupt() error {
+	ret := C.alpm_trans_interrupt(h.ptr)
+	if ret != 0 {
+		return h.LastError()
+	}
+
+	return nil
+}
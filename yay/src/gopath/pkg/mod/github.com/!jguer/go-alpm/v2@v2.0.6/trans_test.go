@@ -0,0 +1,191 @@
+//go:build cgo
+// +build cgo
+
+// trans_test.go - Tests for trans.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// initTestRoot initializes a handle on an empty throwaway root.
+func initTestRoot(t *testing.T) *Handle {
+	t.Helper()
+
+	root := t.TempDir()
+	dbpath := filepath.Join(root, "var/lib/pacman")
+	if err := os.MkdirAll(dbpath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := Initialize(root, dbpath)
+	if err != nil {
+		t.Fatalf("Failed at alpm initialization: %s", err)
+	}
+	t.Cleanup(func() { _ = h.Release() })
+
+	return h
+}
+
+func TestTransEmpty(t *testing.T) {
+	h := initTestRoot(t)
+
+	if err := h.TransInit(TransFlagDBOnly); err != nil {
+		t.Fatalf("TransInit failed: %s", err)
+	}
+	defer h.TransRelease()
+
+	if err := h.TransPrepare(); err != nil {
+		t.Errorf("TransPrepare of an empty transaction failed: %s", err)
+	}
+	if err := h.TransCommit(); err != nil {
+		t.Errorf("TransCommit of an empty transaction failed: %s", err)
+	}
+	if h.TransGetAdd().Len() != 0 || h.TransGetRemove().Len() != 0 {
+		t.Errorf("empty transaction has targets")
+	}
+}
+
+func TestTransNotInitialized(t *testing.T) {
+	h := initTestRoot(t)
+
+	if err := h.TransPrepare(); err == nil {
+		t.Errorf("TransPrepare without TransInit should fail")
+	}
+	if err := h.TransCommit(); err == nil {
+		t.Errorf("TransCommit without TransInit should fail")
+	}
+	if err := h.TransAddPkg(&Package{}); err == nil {
+		t.Errorf("TransAddPkg without TransInit should fail")
+	}
+}
+
+func TestTransError(t *testing.T) {
+	err := error(&TransError{
+		Code: Error(1),
+		MissingDeps: []DepMissing{{
+			Target: "yay",
+			Depend: Depend{Name: "pacman", Version: "6.0", Mod: DepModGE},
+		}},
+		Conflicts: []Conflict{{Package1: "yay", Package2: "yay-bin"}},
+		FileConflicts: []FileConflict{{
+			Target: "yay", Type: FileConflictTarget, File: "usr/bin/yay", CTarget: "yay-bin",
+		}},
+	})
+
+	var transErr *TransError
+	if !errors.As(err, &transErr) {
+		t.Fatalf("error is not a *TransError")
+	}
+	if !errors.Is(err, Error(1)) {
+		t.Errorf("TransError does not unwrap to its code")
+	}
+
+	want := Error(1).Error() + ": yay: requires pacman>=6.0, yay and yay-bin are in conflict, " +
+		"yay: usr/bin/yay exists in both 'yay' and 'yay-bin'"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestTransInstallRemove(t *testing.T) {
+	h := initTestRoot(t)
+	root, _ := h.Root()
+	if err := h.SetLogFile(filepath.Join(root, "pacman.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTestPkg(t, t.TempDir(), testPkg{
+		Name: "go-alpm-test", Version: "1.0-1",
+		Files: map[string]string{"usr/share/go-alpm-test/data": "data\n"},
+	})
+
+	pkg, err := h.LoadPkg(path, true, 0)
+	if err != nil {
+		t.Fatalf("LoadPkg failed: %s", err)
+	}
+
+	if err := h.TransInit(TransFlagDBOnly); err != nil {
+		t.Fatalf("TransInit failed: %s", err)
+	}
+	if err := h.TransAddPkg(pkg); err != nil {
+		t.Fatalf("TransAddPkg failed: %s", err)
+	}
+	if err := h.TransPrepare(); err != nil {
+		t.Fatalf("TransPrepare failed: %s", err)
+	}
+	if err := h.TransCommit(); err != nil {
+		t.Fatalf("TransCommit failed: %s", err)
+	}
+	if err := h.TransRelease(); err != nil {
+		t.Fatalf("TransRelease failed: %s", err)
+	}
+
+	db, _ := h.LocalDB()
+	installed := db.Pkg("go-alpm-test")
+	if installed == nil {
+		t.Fatalf("package was not installed")
+	}
+
+	if err := h.TransInit(TransFlagDBOnly); err != nil {
+		t.Fatalf("TransInit failed: %s", err)
+	}
+	if err := h.TransRemovePkg(installed); err != nil {
+		t.Fatalf("TransRemovePkg failed: %s", err)
+	}
+	if err := h.TransPrepare(); err != nil {
+		t.Fatalf("TransPrepare failed: %s", err)
+	}
+	if err := h.TransCommit(); err != nil {
+		t.Fatalf("TransCommit failed: %s", err)
+	}
+	if err := h.TransRelease(); err != nil {
+		t.Fatalf("TransRelease failed: %s", err)
+	}
+
+	if db.Pkg("go-alpm-test") != nil {
+		t.Errorf("package was not removed")
+	}
+}
+
+func TestTransPrepareMissingDeps(t *testing.T) {
+	h := initTestRoot(t)
+	path := writeTestPkg(t, t.TempDir(), testPkg{
+		Name: "go-alpm-test", Version: "1.0-1", Depends: []string{"go-alpm-missing>=2"},
+	})
+
+	pkg, err := h.LoadPkg(path, false, 0)
+	if err != nil {
+		t.Fatalf("LoadPkg failed: %s", err)
+	}
+
+	if err := h.TransInit(TransFlagDBOnly); err != nil {
+		t.Fatalf("TransInit failed: %s", err)
+	}
+	defer h.TransRelease()
+
+	if err := h.TransAddPkg(pkg); err != nil {
+		t.Fatalf("TransAddPkg failed: %s", err)
+	}
+
+	var transErr *TransError
+	if err := h.TransPrepare(); !errors.As(err, &transErr) {
+		t.Fatalf("got %v, want *TransError", err)
+	}
+	if len(transErr.MissingDeps) != 1 {
+		t.Fatalf("got %d missing deps, want 1", len(transErr.MissingDeps))
+	}
+
+	miss := transErr.MissingDeps[0]
+	if miss.Target != "go-alpm-test" || miss.Depend.Name != "go-alpm-missing" || miss.Depend.Mod != DepModGE {
+		t.Errorf("unexpected missing dependency %+v", miss)
+	}
+}
@@ -17,15 +17,6 @@ import (
 	"unsafe"
 )
 
-// Depend provides a description of a dependency.
-type Depend struct {
-	Name        string
-	Version     string
-	Description string
-	NameHash    uint
-	Mod         DepMod
-}
-
 func convertDepend(dep *C.alpm_depend_t) Depend {
 	return Depend{
 		Name:        C.GoString(dep.name),
@@ -58,15 +49,68 @@ func freeCDepend(dep *C.alpm_depend_t) {
 	C.free(unsafe.Pointer(dep.desc))
 }
 
-func (dep Depend) String() string {
-	return dep.Name + dep.Mod.String() + dep.Version
+// DepMissing describes a dependency of Target that could not be satisfied.
+type DepMissing struct {
+	Target     string
+	Depend     Depend
+	CausingPkg string
+}
+
+func convertDepMissing(miss *C.alpm_depmissing_t) DepMissing {
+	return DepMissing{
+		Target:     C.GoString(miss.target),
+		Depend:     convertDepend(miss.depend),
+		CausingPkg: C.GoString(miss.causingpkg),
+	}
+}
+
+func (miss DepMissing) String() string {
+	return miss.Target + ": requires " + miss.Depend.String()
+}
+
+// Conflict describes a conflict between two packages.
+type Conflict struct {
+	Package1 string
+	Package2 string
+	Reason   Depend
+}
+
+func convertConflict(conflict *C.alpm_conflict_t) Conflict {
+	return Conflict{
+		Package1: C.GoString(conflict.package1),
+		Package2: C.GoString(conflict.package2),
+		Reason:   convertDepend(conflict.reason),
+	}
+}
+
+func (conflict Conflict) String() string {
+	return conflict.Package1 + " and " + conflict.Package2 + " are in conflict"
+}
+
+// FileConflict describes a file owned by Target that is also owned by
+// CTarget or already present on the filesystem.
+type FileConflict struct {
+	Target  string
+	Type    FileConflictType
+	File    string
+	CTarget string
+}
+
+func convertFileConflict(conflict *C.alpm_fileconflict_t) FileConflict {
+	return FileConflict{
+		Target:  C.GoString(conflict.target),
+		Type:    FileConflictType(conflict._type),
+		File:    C.GoString(conflict.file),
+		CTarget: C.GoString(conflict.ctarget),
+	}
 }
 
-// File provides a description of package files.
-type File struct {
-	Name string
-	Size int64
-	Mode uint32
+func (conflict FileConflict) String() string {
+	if conflict.Type == FileConflictTarget {
+		return conflict.Target + ": " + conflict.File + " exists in both '" +
+			conflict.Target + "' and '" + conflict.CTarget + "'"
+	}
+	return conflict.Target + ": " + conflict.File + " exists in filesystem"
 }
 
 func convertFile(file *C.alpm_file_t) (File, error) {
@@ -130,50 +174,38 @@ func (l *list) Empty() bool {
 	return l == nil
 }
 
-type StringList struct {
-	*list
-}
-
-func (l StringList) ForEach(f func(string) error) error {
-	return l.forEach(func(p unsafe.Pointer) error {
-		return f(C.GoString((*C.char)(p)))
+// convertStringList copies a list of strings owned by libalpm.
+func convertStringList(l *list) StringList {
+	strs := StringList{}
+	_ = l.forEach(func(p unsafe.Pointer) error {
+		strs = append(strs, C.GoString((*C.char)(p)))
+		return nil
 	})
+	return strs
 }
 
-func (l StringList) Slice() []string {
-	slice := []string{}
-	_ = l.ForEach(func(s string) error {
-		slice = append(slice, s)
+// convertDependList copies a list of dependencies owned by libalpm.
+func convertDependList(l *list) DependList {
+	deps := DependList{}
+	_ = l.forEach(func(p unsafe.Pointer) error {
+		deps = append(deps, convertDepend((*C.alpm_depend_t)(p)))
 		return nil
 	})
-	return slice
+	return deps
 }
 
-type BackupFile struct {
-	Name string
-	Hash string
-}
-
-type BackupList struct {
-	*list
-}
-
-func (l BackupList) ForEach(f func(BackupFile) error) error {
-	return l.forEach(func(p unsafe.Pointer) error {
+// convertBackupList copies a list of backup files owned by libalpm.
+func convertBackupList(l *list) BackupList {
+	files := BackupList{}
+	_ = l.forEach(func(p unsafe.Pointer) error {
 		bf := (*C.alpm_backup_t)(p)
-		return f(BackupFile{
+		files = append(files, BackupFile{
 			Name: C.GoString(bf.name),
 			Hash: C.GoString(bf.hash),
 		})
-	})
-}
-
-func (l BackupList) Slice() (slice []BackupFile) {
-	_ = l.ForEach(func(f BackupFile) error {
-		slice = append(slice, f)
 		return nil
 	})
-	return
+	return files
 }
 
 type QuestionAny struct {
@@ -0,0 +1,55 @@
+// unneeded.go - Find packages that are not needed anymore.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+// Unneeded returns the packages of db installed as dependencies that are not
+// required by any explicitly installed package anymore, directly or through
+// other unneeded packages, in database order. Packages that are optional
+// dependencies of a needed package are kept like with pacman -Qdt, unless
+// ignoreOptional is set, like with pacman -Qdtt.
+func Unneeded(db IDB, ignoreOptional bool) []IPackage {
+	pkgs := db.PkgCache().Slice()
+	requiredBy := map[string][]string{}
+	unneeded := map[string]bool{}
+
+	for _, pkg := range pkgs {
+		if pkg.Reason() != PkgReasonDepend {
+			continue
+		}
+
+		by := pkg.ComputeRequiredBy()
+		if !ignoreOptional {
+			by = append(by, pkg.ComputeOptionalFor()...)
+		}
+		requiredBy[pkg.Name()] = by
+		unneeded[pkg.Name()] = true
+	}
+
+	// drop packages required by a needed package until nothing changes, so
+	// that chains and cycles of unneeded packages are found as well
+	for changed := true; changed; {
+		changed = false
+		for name := range unneeded {
+			for _, by := range requiredBy[name] {
+				if !unneeded[by] {
+					delete(unneeded, name)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	ret := []IPackage{}
+	for _, pkg := range pkgs {
+		if unneeded[pkg.Name()] {
+			ret = append(ret, pkg)
+		}
+	}
+
+	return ret
+}
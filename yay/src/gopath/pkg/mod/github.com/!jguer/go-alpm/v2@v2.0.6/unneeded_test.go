@@ -0,0 +1,56 @@
+// unneeded_test.go - Tests for Unneeded, using in-memory packages.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm_test
+
+import (
+	"reflect"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/alpmtest"
+)
+
+func TestUnneeded(t *testing.T) {
+	dep := func(name string) *alpmtest.Package {
+		return alpmtest.NewPackage(name, "1.0-1").WithReason(alpm.PkgReasonDepend)
+	}
+
+	db := alpmtest.NewLocalDB(
+		alpmtest.NewPackage("yay", "11.0-1").WithDepends("pacman", "git").WithOptionalDepends("sudo: privilege elevation"),
+		dep("pacman").WithDepends("libarchive"),
+		dep("libarchive"),
+		dep("git").WithProvides("git-core"),
+		dep("sudo"),
+		// orphaned chain
+		dep("python-foo").WithDepends("python"),
+		dep("python"),
+		// orphaned cycle
+		dep("cycle-a").WithDepends("cycle-b"),
+		dep("cycle-b").WithDepends("cycle-a"),
+		// optional dependency of an orphan only
+		dep("python-bar").WithOptionalDepends("python-baz"),
+		dep("python-baz"),
+	)
+
+	names := func(pkgs []alpm.IPackage) []string {
+		names := []string{}
+		for _, pkg := range pkgs {
+			names = append(names, pkg.Name())
+		}
+		return names
+	}
+
+	want := []string{"python-foo", "python", "cycle-a", "cycle-b", "python-bar", "python-baz"}
+	if got := names(alpm.Unneeded(db, false)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	want = []string{"sudo", "python-foo", "python", "cycle-a", "cycle-b", "python-bar", "python-baz"}
+	if got := names(alpm.Unneeded(db, true)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ignoring optional dependencies: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+// values.go - Package values that do not need libalpm.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import "time"
+
+// Depend provides a description of a dependency.
+type Depend struct {
+	Name        string
+	Version     string
+	Description string
+	NameHash    uint
+	Mod         DepMod
+}
+
+func (dep Depend) String() string {
+	return dep.Name + dep.Mod.String() + dep.Version
+}
+
+// File provides a description of package files.
+type File struct {
+	Name string
+	Size int64
+	Mode uint32
+}
+
+// BackupFile is a backup file of a package and the MD5 sum it was installed
+// with.
+type BackupFile struct {
+	Name string
+	Hash string
+}
+
+// PGPKey describes the key that made a signature.
+type PGPKey struct {
+	Fingerprint string
+	UID         string
+	Name        string
+	Email       string
+	Created     time.Time
+	// Expires is the zero time if the key never expires.
+	Expires    time.Time
+	Length     uint
+	Revoked    bool
+	PubkeyAlgo byte
+}
+
+// SigResult is the result of checking a single signature.
+type SigResult struct {
+	Key      PGPKey
+	Status   SigStatus
+	Validity SigValidity
+}
+
+// SigList holds the results of checking every signature of a package or
+// database.
+type SigList []SigResult
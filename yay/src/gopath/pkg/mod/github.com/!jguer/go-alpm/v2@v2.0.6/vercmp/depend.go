@@ -0,0 +1,120 @@
+// depend.go - Pure Go dependency strings.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package vercmp
+
+import "strings"
+
+// DepMod is a dependency version constraint. The values match alpm.DepMod.
+type DepMod uint
+
+const (
+	DepModAny DepMod = iota + 1 // Any version.
+	DepModEq                    // Specific version.
+	DepModGE                    // Test for >= some version.
+	DepModLE                    // Test for <= some version.
+	DepModGT                    // Test for > some version.
+	DepModLT                    // Test for < some version.
+)
+
+func (mod DepMod) String() string {
+	switch mod {
+	case DepModEq:
+		return "="
+	case DepModGE:
+		return ">="
+	case DepModLE:
+		return "<="
+	case DepModGT:
+		return ">"
+	case DepModLT:
+		return "<"
+	}
+	return ""
+}
+
+// Depend is a parsed dependency string such as "glibc>=2.12: for foo".
+type Depend struct {
+	Name        string
+	Version     string
+	Description string
+	Mod         DepMod
+}
+
+// ParseDepend parses a dependency string the way alpm_dep_from_string does.
+// An optional description follows ": ", so that an epoch in the version is
+// not mistaken for one.
+func ParseDepend(s string) Depend {
+	var dep Depend
+
+	if i := strings.Index(s, ": "); i >= 0 {
+		s, dep.Description = s[:i], s[i+2:]
+	}
+
+	var op int
+	switch {
+	case strings.IndexByte(s, '<') >= 0:
+		op = strings.IndexByte(s, '<')
+		dep.Mod = DepModLT
+	case strings.IndexByte(s, '>') >= 0:
+		op = strings.IndexByte(s, '>')
+		dep.Mod = DepModGT
+	case strings.IndexByte(s, '=') >= 0:
+		op = strings.IndexByte(s, '=')
+		dep.Mod = DepModEq
+	default:
+		dep.Name, dep.Mod = s, DepModAny
+		return dep
+	}
+
+	dep.Name, dep.Version = s[:op], s[op+1:]
+	if dep.Mod != DepModEq && strings.HasPrefix(dep.Version, "=") {
+		dep.Version = dep.Version[1:]
+		if dep.Mod == DepModLT {
+			dep.Mod = DepModLE
+		} else {
+			dep.Mod = DepModGE
+		}
+	}
+
+	return dep
+}
+
+// String returns the dependency string without its description.
+func (dep Depend) String() string {
+	return dep.Name + dep.Mod.String() + dep.Version
+}
+
+// Satisfies reports whether a package or provision called name at version
+// satisfies dep. version is empty for unversioned provisions, which only
+// satisfy dependencies without a version constraint.
+func (dep Depend) Satisfies(name, version string) bool {
+	if name != dep.Name {
+		return false
+	}
+	if dep.Mod == DepModAny {
+		return true
+	}
+	if version == "" {
+		return false
+	}
+
+	cmp := Compare(version, dep.Version)
+	switch dep.Mod {
+	case DepModEq:
+		return cmp == 0
+	case DepModGE:
+		return cmp >= 0
+	case DepModLE:
+		return cmp <= 0
+	case DepModGT:
+		return cmp > 0
+	case DepModLT:
+		return cmp < 0
+	}
+
+	return false
+}
@@ -0,0 +1,162 @@
+// vercmp.go - Pure Go version comparison.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+// Package vercmp compares package versions and parses dependency strings the
+// same way libalpm does, without cgo.
+package vercmp
+
+import "strings"
+
+// Compare compares two versions of the form [epoch:]version[-pkgrel]. The
+// result is <0 if v1 is older than v2, 0 if they are equal and >0 if v1 is
+// newer, exactly like alpm_pkg_vercmp.
+func Compare(v1, v2 string) int {
+	if v1 == v2 {
+		return 0
+	}
+
+	epoch1, ver1, rel1, hasRel1 := parseEVR(v1)
+	epoch2, ver2, rel2, hasRel2 := parseEVR(v2)
+
+	ret := rpmvercmp(epoch1, epoch2)
+	if ret == 0 {
+		ret = rpmvercmp(ver1, ver2)
+		// the pkgrel is only compared if both versions have one, even empty
+		if ret == 0 && hasRel1 && hasRel2 {
+			ret = rpmvercmp(rel1, rel2)
+		}
+	}
+
+	return ret
+}
+
+// parseEVR splits a version into its epoch, version and release. The epoch
+// defaults to "0". hasRel tells whether there was a "-", as "1.0-" has an
+// empty release where "1.0" has none.
+func parseEVR(evr string) (epoch, version, release string, hasRel bool) {
+	s := 0
+	for s < len(evr) && isDigit(evr[s]) {
+		s++
+	}
+
+	version = evr
+	epoch = "0"
+	if s < len(evr) && evr[s] == ':' {
+		if s > 0 {
+			epoch = evr[:s]
+		}
+		version = evr[s+1:]
+	}
+
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		version, release, hasRel = version[:i], version[i+1:], true
+	}
+
+	return epoch, version, release, hasRel
+}
+
+// rpmvercmp compares two version strings segment by segment, where segments
+// are runs of digits or letters separated by any other characters.
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	one, two := 0, 0
+	for one < len(a) && two < len(b) {
+		ptr1, ptr2 := one, two
+
+		for one < len(a) && !isAlnum(a[one]) {
+			one++
+		}
+		for two < len(b) && !isAlnum(b[two]) {
+			two++
+		}
+
+		if one == len(a) || two == len(b) {
+			break
+		}
+
+		// different separator lengths end the comparison
+		if one-ptr1 != two-ptr2 {
+			if one-ptr1 < two-ptr2 {
+				return -1
+			}
+			return 1
+		}
+
+		ptr1, ptr2 = one, two
+
+		isNum := isDigit(a[ptr1])
+		if isNum {
+			for ptr1 < len(a) && isDigit(a[ptr1]) {
+				ptr1++
+			}
+			for ptr2 < len(b) && isDigit(b[ptr2]) {
+				ptr2++
+			}
+		} else {
+			for ptr1 < len(a) && isAlpha(a[ptr1]) {
+				ptr1++
+			}
+			for ptr2 < len(b) && isAlpha(b[ptr2]) {
+				ptr2++
+			}
+		}
+
+		seg1, seg2 := a[one:ptr1], b[two:ptr2]
+
+		// segments of different types: numeric is newer than alpha
+		if seg2 == "" {
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		if isNum {
+			seg1 = strings.TrimLeft(seg1, "0")
+			seg2 = strings.TrimLeft(seg2, "0")
+
+			// the longer number wins
+			if len(seg1) > len(seg2) {
+				return 1
+			}
+			if len(seg2) > len(seg1) {
+				return -1
+			}
+		}
+
+		if c := strings.Compare(seg1, seg2); c != 0 {
+			return c
+		}
+
+		one, two = ptr1, ptr2
+	}
+
+	if one == len(a) && two == len(b) {
+		return 0
+	}
+
+	// A remaining alpha segment never beats an empty string: if a is empty
+	// and b is not alpha, or a is alpha, b is newer. Otherwise a is newer.
+	if (one == len(a) && !isAlpha(b[two])) || (one < len(a) && isAlpha(a[one])) {
+		return -1
+	}
+	return 1
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isAlpha(c)
+}
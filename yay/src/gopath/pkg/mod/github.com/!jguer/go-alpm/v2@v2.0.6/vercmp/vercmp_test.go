@@ -0,0 +1,155 @@
+// vercmp_test.go - Tests for vercmp.go and depend.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package vercmp
+
+import "testing"
+
+// compareTests are the cases of pacman's vercmptest.sh.
+var compareTests = []struct {
+	v1, v2 string
+	want   int
+}{
+	// all similar length, no pkgrel
+	{"1.5.0", "1.5.0", 0},
+	{"1.5.1", "1.5.0", 1},
+	// mixed length
+	{"1.5.1", "1.5", 1},
+	// with pkgrel, simple
+	{"1.5.0-1", "1.5.0-1", 0},
+	{"1.5.0-1", "1.5.0-2", -1},
+	{"1.5.0-1", "1.5.1-1", -1},
+	{"1.5.0-2", "1.5.1-1", -1},
+	// with pkgrel, mixed lengths
+	{"1.5-1", "1.5.1-1", -1},
+	{"1.5-2", "1.5.1-1", -1},
+	{"1.5-2", "1.5.1-2", -1},
+	// mixed pkgrel inclusion
+	{"1.5", "1.5-1", 0},
+	{"1.5-1", "1.5", 0},
+	{"1.1-1", "1.1", 0},
+	{"1.0-1", "1.1", -1},
+	// empty pkgrel
+	{"1.0-", "1.0-1", -1},
+	{"1.0-", "1.0", 0},
+	{"1.1-1", "1.0", 1},
+	// alphanumeric versions
+	{"1.5b-1", "1.5-1", -1},
+	{"1.5b", "1.5", -1},
+	{"1.5b-1", "1.5", -1},
+	{"1.5b", "1.5.1", -1},
+	// from the manpage
+	{"1.0a", "1.0alpha", -1},
+	{"1.0alpha", "1.0b", -1},
+	{"1.0b", "1.0beta", -1},
+	{"1.0beta", "1.0rc", -1},
+	{"1.0rc", "1.0", -1},
+	// alpha-dotted versions
+	{"1.5.a", "1.5", 1},
+	{"1.5.b", "1.5.a", 1},
+	{"1.5.1", "1.5.b", 1},
+	// alpha dots and dashes
+	{"1.5.b-1", "1.5.b", 0},
+	{"1.5-1", "1.5.b", -1},
+	// same/similar content, differing separators
+	{"2.0", "2_0", 0},
+	{"2.0_a", "2_0.a", 0},
+	{"2.0a", "2.0.a", -1},
+	{"2___a", "2_a", 1},
+	// epoch included version comparisons
+	{"0:1.0", "0:1.0", 0},
+	{"0:1.0", "0:1.1", -1},
+	{"1:1.0", "0:1.0", 1},
+	{"1:1.0", "0:1.1", 1},
+	{"1:1.0", "2:1.1", -1},
+	// epoch + sometimes present pkgrel
+	{"1:1.0", "0:1.0-1", 1},
+	{"1:1.0-1", "0:1.1-1", 1},
+	// epoch included on one version
+	{"0:1.0", "1.0", 0},
+	{"0:1.0", "1.1", -1},
+	{"0:1.1", "1.0", 1},
+	{"1:1.0", "1.0", 1},
+	{"1:1.0", "1.1", 1},
+	{"1:1.1", "1.1", 1},
+	// leading zeroes and long numbers
+	{"1.010", "1.10", 0},
+	{"1.0001", "1.1", 0},
+	{"20210101", "9999", 1},
+	{"1.0", "1.0.", -1},
+	{"", "1", -1},
+}
+
+func TestCompare(t *testing.T) {
+	for _, tt := range compareTests {
+		if got := Compare(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.v1, tt.v2, got, tt.want)
+		}
+		if got := Compare(tt.v2, tt.v1); got != -tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.v2, tt.v1, got, -tt.want)
+		}
+	}
+}
+
+func TestParseDepend(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Depend
+	}{
+		{"glibc", Depend{Name: "glibc", Mod: DepModAny}},
+		{"glibc>=2.12", Depend{Name: "glibc", Version: "2.12", Mod: DepModGE}},
+		{"glibc>=2.12: for foo", Depend{Name: "glibc", Version: "2.12", Mod: DepModGE, Description: "for foo"}},
+		{"glibc: for foo", Depend{Name: "glibc", Mod: DepModAny, Description: "for foo"}},
+		{"glibc<=2.12", Depend{Name: "glibc", Version: "2.12", Mod: DepModLE}},
+		{"glibc<2.12", Depend{Name: "glibc", Version: "2.12", Mod: DepModLT}},
+		{"glibc>2.12", Depend{Name: "glibc", Version: "2.12", Mod: DepModGT}},
+		{"glibc=1:2.12-1", Depend{Name: "glibc", Version: "1:2.12-1", Mod: DepModEq}},
+		{"sh=1:5.0: with epoch", Depend{Name: "sh", Version: "1:5.0", Mod: DepModEq, Description: "with epoch"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseDepend(tt.in); got != tt.want {
+			t.Errorf("ParseDepend(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDependString(t *testing.T) {
+	for _, s := range []string{"glibc", "glibc>=2.12", "glibc<2", "glibc=1:2.12-1"} {
+		if got := ParseDepend(s).String(); got != s {
+			t.Errorf("ParseDepend(%q).String() = %q", s, got)
+		}
+	}
+}
+
+func TestDependSatisfies(t *testing.T) {
+	tests := []struct {
+		dep           string
+		name, version string
+		want          bool
+	}{
+		{"glibc", "glibc", "2.33-5", true},
+		{"glibc", "glibc", "", true},
+		{"glibc", "musl", "2.33-5", false},
+		{"glibc>=2.12", "glibc", "2.33-5", true},
+		{"glibc>=2.12", "glibc", "2.12", true},
+		{"glibc>=2.12", "glibc", "2.11-1", false},
+		{"glibc>=2.12", "glibc", "", false},
+		{"glibc>2.33", "glibc", "2.33-5", false},
+		{"glibc>2.33-4", "glibc", "2.33-5", true},
+		{"glibc<2.34", "glibc", "2.33-5", true},
+		{"glibc<=2.33", "glibc", "2.33-5", true},
+		{"glibc=2.33", "glibc", "2.33-5", true},
+		{"glibc=2.33-4", "glibc", "2.33-5", false},
+		{"glibc=2.33", "glibc", "1:2.33-5", false},
+	}
+
+	for _, tt := range tests {
+		if got := ParseDepend(tt.dep).Satisfies(tt.name, tt.version); got != tt.want {
+			t.Errorf("%s satisfied by %s %q: got %t, want %t", tt.dep, tt.name, tt.version, got, tt.want)
+		}
+	}
+}
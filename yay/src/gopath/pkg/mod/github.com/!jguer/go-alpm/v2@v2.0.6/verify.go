@@ -0,0 +1,44 @@
+//go:build cgo
+// +build cgo
+
+// verify.go - Verify installed files against the package mtree.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import "path/filepath"
+
+// Verify checks the files of the installed package under root against its
+// mtree, like pacman -Qkk: type, permissions, owner, modification time,
+// size, symlink target and checksum. Like pacman, only the type,
+// permissions and owner of backup files are checked.
+func (pkg *Package) Verify(root string) ([]FileDiscrepancy, error) {
+	dbPath, err := pkg.handle.DBPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyPackage(newVerifyTarget(pkg, filepath.Join(dbPath, "local")), root)
+}
+
+// VerifyLocalDB verifies every installed package with Verify, using up to
+// workers goroutines.
+func (h *Handle) VerifyLocalDB(workers int) ([]PackageVerification, error) {
+	root, err := h.Root()
+	if err != nil {
+		return nil, err
+	}
+	dbPath, err := h.DBPath()
+	if err != nil {
+		return nil, err
+	}
+	db, err := h.LocalDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return VerifyPackages(db.PkgCache().Slice(), root, filepath.Join(dbPath, "local"), workers), nil
+}
@@ -0,0 +1,197 @@
+// verify_test.go - Tests for mtree parsing and file verification, using a
+// fabricated root and local database.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm_test
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Jguer/go-alpm/v2/alpmtest"
+)
+
+func TestParseMtree(t *testing.T) {
+	mtree := `#mtree
+/set type=file uid=0 gid=0 mode=644
+./.BUILDINFO time=1624000000.0 size=10 md5digest=abc
+./.PKGINFO time=1624000000.0 size=10
+./usr time=1624000000.0 mode=755 type=dir
+./usr/bin/my\040tool time=1624000000.500000000 mode=755 size=3 sha256digest=def
+/set uid=1000
+./usr/lib/libfoo.so time=1624000000.0 type=link link=libfoo\040x.so
+`
+	entries, err := alpm.ParseMtree(strings.NewReader(mtree))
+	if err != nil {
+		t.Fatalf("ParseMtree failed: %s", err)
+	}
+
+	want := []alpm.MtreeEntry{
+		{Path: "usr", Type: "dir", Mode: 0755, ModTime: time.Unix(1624000000, 0)},
+		{Path: "usr/bin/my tool", Type: "file", Mode: 0755, Size: 3, ModTime: time.Unix(1624000000, 500000000), SHA256: "def"},
+		{Path: "usr/lib/libfoo.so", Type: "link", Mode: 0644, UID: 1000, ModTime: time.Unix(1624000000, 0), Link: "libfoo x.so"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v, want %+v", entries, want)
+	}
+
+	if _, err := alpm.ParseMtree(strings.NewReader("./usr mode=9\n")); err == nil {
+		t.Errorf("invalid mode did not fail")
+	}
+}
+
+// testRoot installs files in root and writes the gzipped mtree of a package
+// describing them as they were installed.
+func testRoot(t *testing.T, root, localDir string, pkg alpm.IPackage, files map[string]string) {
+	t.Helper()
+
+	mtime := time.Unix(1624000000, 0)
+	var b strings.Builder
+	fmt.Fprintf(&b, "#mtree\n/set type=file uid=%d gid=%d mode=644\n", os.Getuid(), os.Getgid())
+
+	for _, name := range []string{"etc", "usr", "usr/bin"} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&b, "./%s time=%d.0 mode=755 type=dir\n", name, mtime.Unix())
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if strings.HasPrefix(content, "->") {
+			if err := os.Symlink(content[2:], path); err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintf(&b, "./%s time=%d.0 type=link link=%s\n", name, mtime.Unix(), content[2:])
+			continue
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256([]byte(content))
+		fmt.Fprintf(&b, "./%s time=%d.0 size=%d sha256digest=%s\n", name, mtime.Unix(), len(content), hex.EncodeToString(sum[:]))
+	}
+
+	dir := filepath.Join(localDir, pkg.Name()+"-"+pkg.Version())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, "mtree"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(b.String())); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyPackages(t *testing.T) {
+	root, localDir := t.TempDir(), t.TempDir()
+
+	foo := alpmtest.NewPackage("foo", "1.0-1").WithBackup(alpm.BackupFile{Name: "etc/foo.conf"})
+	bar := alpmtest.NewPackage("bar", "2.0-1")
+	missing := alpmtest.NewPackage("missing", "1.0-1")
+
+	testRoot(t, root, localDir, foo, map[string]string{
+		"etc/foo.conf":    "a=1\n",
+		"usr/bin/foo":     "foo",
+		"usr/bin/foo-sum": "abc",
+		"usr/bin/foo-len": "abc",
+		"usr/bin/foo-old": "abc",
+		"usr/bin/foo-mod": "abc",
+		"usr/bin/foo-rm":  "abc",
+		"usr/bin/foo-ln":  "->foo",
+	})
+	testRoot(t, root, localDir, bar, map[string]string{
+		"usr/bin/bar":    "bar",
+		"usr/bin/bar-ln": "->bar",
+	})
+
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Unix(1624000000, 0)
+		if err := os.Chtimes(filepath.Join(root, name), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("etc/foo.conf", "a=2\nb=3\n")
+	write("usr/bin/foo-sum", "xyz")
+	write("usr/bin/foo-len", "abcd")
+	if err := os.Chtimes(filepath.Join(root, "usr/bin/foo-old"), time.Unix(1, 0), time.Unix(1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "usr/bin/foo-mod"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "usr/bin/foo-rm")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "usr/bin/foo-ln")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("bar", filepath.Join(root, "usr/bin/foo-ln")); err != nil {
+		t.Fatal(err)
+	}
+
+	results := alpm.VerifyPackages([]alpm.IPackage{foo, bar, missing}, root, localDir, 2)
+	if len(results) != 3 || results[0].Package != foo || results[1].Package != bar {
+		t.Fatalf("unexpected results %v", results)
+	}
+
+	got := map[string]alpm.DiscrepancyKind{}
+	for _, d := range results[0].Discrepancies {
+		if _, ok := got[d.File]; ok {
+			t.Errorf("several discrepancies for %s", d)
+		}
+		got[d.File] = d.Kind
+	}
+	want := map[string]alpm.DiscrepancyKind{
+		"usr/bin/foo-sum": alpm.FileChecksumMismatch,
+		"usr/bin/foo-len": alpm.FileSizeMismatch,
+		"usr/bin/foo-old": alpm.FileMTimeMismatch,
+		"usr/bin/foo-mod": alpm.FileModeMismatch,
+		"usr/bin/foo-rm":  alpm.FileMissing,
+		"usr/bin/foo-ln":  alpm.FileLinkMismatch,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if len(results[1].Discrepancies) != 0 || results[1].Err != nil {
+		t.Errorf("bar is intact but got %v %v", results[1].Discrepancies, results[1].Err)
+	}
+	if results[2].Err == nil {
+		t.Errorf("package without mtree did not fail")
+	}
+}
+
+func TestFileDiscrepancyString(t *testing.T) {
+	d := alpm.FileDiscrepancy{Package: "foo", File: "usr/bin/foo", Kind: alpm.FileSizeMismatch, Expected: "3", Actual: "4"}
+	if s := d.String(); s != "foo: /usr/bin/foo (size mismatch: expected 3, got 4)" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
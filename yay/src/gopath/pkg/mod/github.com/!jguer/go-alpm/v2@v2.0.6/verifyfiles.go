@@ -0,0 +1,231 @@
+// verifyfiles.go - Verify the files of packages against their mtree.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// FileDiscrepancy is an installed file that differs from its package.
+// Expected and Actual describe the difference when it has a value, such as
+// a size.
+type FileDiscrepancy struct {
+	Package string
+	// File is relative to the root.
+	File     string
+	Kind     DiscrepancyKind
+	Expected string
+	Actual   string
+}
+
+func (d FileDiscrepancy) String() string {
+	if d.Expected == "" && d.Actual == "" {
+		return fmt.Sprintf("%s: /%s (%s)", d.Package, d.File, d.Kind)
+	}
+	return fmt.Sprintf("%s: /%s (%s: expected %s, got %s)", d.Package, d.File, d.Kind, d.Expected, d.Actual)
+}
+
+// PackageVerification is the outcome of verifying an installed package. Err
+// is set if the mtree of the package could not be read.
+type PackageVerification struct {
+	Package       IPackage
+	Discrepancies []FileDiscrepancy
+	Err           error
+}
+
+// VerifyPackages verifies pkgs, installed under root and described in the
+// local database directory localDir, using up to workers goroutines. The
+// results are in the order of pkgs.
+func VerifyPackages(pkgs []IPackage, root, localDir string, workers int) []PackageVerification {
+	if workers < 1 {
+		workers = 1
+	}
+
+	// the packages are only read here, as libalpm is not thread safe
+	targets := make([]verifyTarget, len(pkgs))
+	for i, pkg := range pkgs {
+		targets[i] = newVerifyTarget(pkg, localDir)
+	}
+
+	results := make([]PackageVerification, len(pkgs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				discrepancies, err := verifyPackage(targets[i], root)
+				results[i] = PackageVerification{pkgs[i], discrepancies, err}
+			}
+		}()
+	}
+
+	for i := range pkgs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// verifyTarget holds what verifying a package needs from it.
+type verifyTarget struct {
+	name    string
+	mtree   string
+	backups map[string]bool
+}
+
+func newVerifyTarget(pkg IPackage, localDir string) verifyTarget {
+	target := verifyTarget{
+		name:    pkg.Name(),
+		mtree:   filepath.Join(localDir, pkg.Name()+"-"+pkg.Version(), "mtree"),
+		backups: map[string]bool{},
+	}
+	for _, backup := range pkg.Backup().Slice() {
+		target.backups[backup.Name] = true
+	}
+	return target
+}
+
+func verifyPackage(target verifyTarget, root string) ([]FileDiscrepancy, error) {
+	entries, err := ReadMtree(target.mtree)
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancies := []FileDiscrepancy{}
+	for _, entry := range entries {
+		for _, d := range verifyEntry(root, entry, target.backups[entry.Path]) {
+			d.Package = target.name
+			discrepancies = append(discrepancies, d)
+		}
+	}
+
+	return discrepancies, nil
+}
+
+func verifyEntry(root string, entry MtreeEntry, backup bool) []FileDiscrepancy {
+	path := filepath.Join(root, entry.Path)
+	found := func(kind DiscrepancyKind, expected, actual string) []FileDiscrepancy {
+		return []FileDiscrepancy{{File: entry.Path, Kind: kind, Expected: expected, Actual: actual}}
+	}
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return found(FileMissing, "", "")
+	}
+	if err != nil {
+		return found(FileUnreadable, "", err.Error())
+	}
+
+	if typ := mtreeType(info.Mode()); entry.Type != "" && typ != entry.Type {
+		return found(FileTypeMismatch, entry.Type, typ)
+	}
+
+	var discrepancies []FileDiscrepancy
+	if entry.Type != "link" {
+		if mode := mtreeMode(info.Mode()); mode != entry.Mode {
+			discrepancies = append(discrepancies, found(FileModeMismatch, fmt.Sprintf("%o", entry.Mode), fmt.Sprintf("%o", mode))...)
+		}
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && (int(st.Uid) != entry.UID || int(st.Gid) != entry.GID) {
+		discrepancies = append(discrepancies, found(FileOwnerMismatch,
+			fmt.Sprintf("%d:%d", entry.UID, entry.GID), fmt.Sprintf("%d:%d", st.Uid, st.Gid))...)
+	}
+
+	switch {
+	case entry.Type == "link":
+		link, err := os.Readlink(path)
+		if err != nil {
+			discrepancies = append(discrepancies, found(FileUnreadable, "", err.Error())...)
+		} else if link != entry.Link {
+			discrepancies = append(discrepancies, found(FileLinkMismatch, entry.Link, link)...)
+		}
+	case entry.Type == "file" && !backup:
+		if !entry.ModTime.IsZero() && info.ModTime().Unix() != entry.ModTime.Unix() {
+			discrepancies = append(discrepancies, found(FileMTimeMismatch,
+				strconv.FormatInt(entry.ModTime.Unix(), 10), strconv.FormatInt(info.ModTime().Unix(), 10))...)
+		}
+		// the content only needs hashing if the size is right
+		if info.Size() != entry.Size {
+			discrepancies = append(discrepancies, found(FileSizeMismatch,
+				strconv.FormatInt(entry.Size, 10), strconv.FormatInt(info.Size(), 10))...)
+		} else {
+			discrepancies = append(discrepancies, verifyChecksum(path, entry)...)
+		}
+	}
+
+	return discrepancies
+}
+
+func verifyChecksum(path string, entry MtreeEntry) []FileDiscrepancy {
+	var h hash.Hash
+	var expected string
+	switch {
+	case entry.SHA256 != "":
+		h, expected = sha256.New(), entry.SHA256
+	case entry.MD5 != "":
+		h, expected = md5.New(), entry.MD5
+	default:
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err == nil {
+		_, err = io.Copy(h, f)
+		f.Close()
+	}
+	if err != nil {
+		return []FileDiscrepancy{{File: entry.Path, Kind: FileUnreadable, Actual: err.Error()}}
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return []FileDiscrepancy{{File: entry.Path, Kind: FileChecksumMismatch, Expected: expected, Actual: actual}}
+	}
+	return nil
+}
+
+// mtreeType returns the mtree type of a file mode.
+func mtreeType(mode os.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&os.ModeSymlink != 0:
+		return "link"
+	case mode.IsRegular():
+		return "file"
+	}
+	return "other"
+}
+
+// mtreeMode returns the permission bits of a file mode as in an mtree.
+func mtreeMode(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		m |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= 01000
+	}
+	return m
+}
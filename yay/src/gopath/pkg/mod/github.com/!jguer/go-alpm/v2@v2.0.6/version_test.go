@@ -0,0 +1,70 @@
+//go:build cgo
+// +build cgo
+
+// version_test.go - Tests for version.go.
+//
+// Copyright (c) 2013 The go-alpm Authors
+//
+// MIT Licensed. See LICENSE for details.
+
+package alpm
+
+import (
+	"testing"
+
+	"github.com/Jguer/go-alpm/v2/vercmp"
+)
+
+// TestVerCmpMatchesPureGo checks that the pure Go vercmp package agrees with
+// libalpm.
+func TestVerCmpMatchesPureGo(t *testing.T) {
+	versions := []string{
+		"", "1", "1.0", "1.0.", "1.0-", "1.0-1", "1.0-2", "1.0.1", "1.0a", "1.0alpha",
+		"1.0b", "1.0beta", "1.0rc", "1.0.a", "1.0_a", "1_0.a", "1___a", "1.010",
+		"1.10", "0:1.0", "1:1.0", "1:1.0-1", "2:0.1", "20210101", "9999",
+		"1.0+r12+gabcdef", "1.0.r12.gabcdef-1", "1.0~rc1",
+	}
+
+	sign := func(i int) int {
+		switch {
+		case i < 0:
+			return -1
+		case i > 0:
+			return 1
+		}
+		return 0
+	}
+
+	for _, v1 := range versions {
+		for _, v2 := range versions {
+			if want, got := sign(VerCmp(v1, v2)), sign(vercmp.Compare(v1, v2)); got != want {
+				t.Errorf("vercmp.Compare(%q, %q) = %d, VerCmp gives %d", v1, v2, got, want)
+			}
+		}
+	}
+}
+
+func TestParseDependMatchesAlpm(t *testing.T) {
+	h := initTestRoot(t)
+	depends := []string{"glibc", "glibc>=2.12", "zlib<2: reason", "sh=1:5.0-1: with epoch", "bash<=5", "perl>5.30"}
+	path := writeTestPkg(t, t.TempDir(), testPkg{Name: "go-alpm-test", Version: "1.0-1", Depends: depends})
+
+	pkg, err := h.LoadPkg(path, false, 0)
+	if err != nil {
+		t.Fatalf("LoadPkg failed: %s", err)
+	}
+
+	got := pkg.Depends().Slice()
+	if len(got) != len(depends) {
+		t.Fatalf("got %d depends, want %d", len(got), len(depends))
+	}
+
+	for i, s := range depends {
+		want := vercmp.ParseDepend(s)
+		dep := got[i]
+		if dep.Name != want.Name || dep.Version != want.Version ||
+			dep.Description != want.Description || dep.Mod != DepMod(want.Mod) {
+			t.Errorf("%q: libalpm gives %+v, vercmp gives %+v", s, dep, want)
+		}
+	}
+}